@@ -16,11 +16,24 @@ echo "\nrecord circuit groth16:"
 echo "\nrecord circuit plonk:"
 ./circuits -record -iterations 1 -backend "plonk"
 
+echo "\nrecord tag circuit groth16:"
+./circuits -record-tag -iterations 1
+echo "\nrecord tag circuit plonk:"
+./circuits -record-tag -iterations 1 -backend "plonk"
+
+echo "\nrecord chacha circuit groth16:"
+./circuits -record-chacha -iterations 1
+echo "\nrecord chacha circuit plonk:"
+./circuits -record-chacha -iterations 1 -backend "plonk"
+
 echo "\noracle circuit groth16:"
 ./circuits -tls13-oracle -iterations 1
 echo "\noracle circuit plonk:"
 ./circuits -tls13-oracle -iterations 1 -backend "plonk"
 
+echo "\noracle circuit TLS_AES_256_GCM_SHA384 groth16:"
+./circuits -tls13-oracle -iterations 1 -cipher-suite "TLS_AES_256_GCM_SHA384"
+
 ## basic circuits
 echo "\nshacal2 circuit groth16:"
 ./circuits -shacal2 -iterations 2
@@ -53,6 +66,9 @@ echo "\ngcm dynamic circuit plonk:"
 echo "\nsha256 dynamic circuit groth16:"
 ./circuits -sha256 -iterations 2 -byte-size 32
 
+echo "\nsha384 dynamic circuit groth16:"
+./circuits -sha384 -iterations 2 -byte-size 32
+
 echo "\nxor dynamic circuit groth16:"
 ./circuits -xor -iterations 2 -byte-size 16
 
@@ -15,7 +15,20 @@ echo "\nrecord circuit groth16:"
 echo "\nrecord circuit plonk:"
 ./circuits -record -iterations 1 -backend "plonk" -compile
 
+echo "\nrecord tag circuit groth16:"
+./circuits -record-tag -iterations 1 -compile
+echo "\nrecord tag circuit plonk:"
+./circuits -record-tag -iterations 1 -backend "plonk" -compile
+
+echo "\nrecord chacha circuit groth16:"
+./circuits -record-chacha -iterations 1 -compile
+echo "\nrecord chacha circuit plonk:"
+./circuits -record-chacha -iterations 1 -backend "plonk" -compile
+
 echo "\noracle circuit groth16:"
 ./circuits -tls13-oracle -compile -iterations 1
 echo "\noracle circuit plonk:"
 ./circuits -tls13-oracle -backend "plonk" -iterations 1 -compile
+
+echo "\noracle circuit TLS_AES_256_GCM_SHA384 groth16:"
+./circuits -tls13-oracle -compile -iterations 1 -cipher-suite "TLS_AES_256_GCM_SHA384"
@@ -55,20 +55,31 @@ type GCM struct {
 	aes AES
 }
 
+// lookup based aes gadgets, implemented by LookUpAES128 and LookUpAES256
+type LookUpAES interface {
+	Encrypt(key []frontend.Variable, pt [16]frontend.Variable) [16]frontend.Variable
+	VariableXor(a frontend.Variable, b frontend.Variable, size int) frontend.Variable
+	createIV(counter frontend.Variable, iv []frontend.Variable)
+}
+
 func NewGCMlu(api frontend.API, aes LookUpAES128) GCM2 {
-	return GCM2{api: api, aes: aes}
+	return GCM2{api: api, aes: &aes}
+}
+
+func NewGCMlu256(api frontend.API, aes LookUpAES256) GCM2 {
+	return GCM2{api: api, aes: &aes}
 }
 
 type GCM2 struct {
 	api frontend.API
-	aes LookUpAES128
+	aes LookUpAES
 }
 
 // aes gcm encryption
-func (gcm *GCM2) Assert2(key [16]frontend.Variable, iv [12]frontend.Variable, chunkIndex frontend.Variable, plaintext, ciphertext []frontend.Variable) {
+func (gcm *GCM2) Assert2(key []frontend.Variable, iv [12]frontend.Variable, chunkIndex frontend.Variable, plaintext, ciphertext []frontend.Variable) {
 
 	inputSize := len(plaintext)
-	numberBlocks := int(inputSize / 16)
+	numberBlocks := (inputSize + 15) / 16
 
 	var counterBlock [16]frontend.Variable
 	for i := 0; i < 12; i++ {
@@ -92,19 +103,45 @@ func (gcm *GCM2) Assert2(key [16]frontend.Variable, iv [12]frontend.Variable, ch
 		gcm.aes.createIV(idx, counterBlock[:])
 
 		// ivCounter := GetIV(gcm.api, iv, idx)
-		// intermediate := gcm.aes.Encrypt(key[:], ivCounter)
+		// intermediate := gcm.aes.Encrypt(key, ivCounter)
 		//ct := gcm.Xor16(intermediate, ptBlock)
 
-		keystream := gcm.aes.Encrypt(key[:], counterBlock)
+		keystream := gcm.aes.Encrypt(key, counterBlock)
 
-		// check ciphertext to plaintext constraints
-		for i := 0; i < 16; i++ {
+		// check ciphertext to plaintext constraints, last block may be partial
+		for i := 0; i < 16 && eIndex+i < inputSize; i++ {
 			// gcm.api.AssertIsEqual(ctBlock[i], ct[i])
 			gcm.api.AssertIsEqual(ciphertext[eIndex+i], gcm.aes.VariableXor(keystream[i], plaintext[eIndex+i], 8))
 		}
 	}
 }
 
+// aes gcm authentication tag of a full record, counter 1 is reserved for the tag mask
+func (gcm *GCM2) AssertTag(key []frontend.Variable, iv [12]frontend.Variable, aad, ciphertext []frontend.Variable, tag [16]frontend.Variable) {
+
+	// hash key H = E(K, 0^128)
+	var zeros [16]frontend.Variable
+	for i := 0; i < 16; i++ {
+		zeros[i] = 0
+	}
+	h := gcm.aes.Encrypt(key, zeros)
+
+	// tag mask E(K, J0) with J0 = iv || 0^31 || 1
+	var j0 [16]frontend.Variable
+	for i := 0; i < 12; i++ {
+		j0[i] = iv[i]
+	}
+	gcm.aes.createIV(1, j0[:])
+	mask := gcm.aes.Encrypt(key, j0)
+
+	ghash := NewGHash(gcm.api)
+	s := ghash.Hash(h, aad, ciphertext)
+
+	for i := 0; i < 16; i++ {
+		gcm.api.AssertIsEqual(tag[i], gcm.aes.VariableXor(s[i], mask[i], 8))
+	}
+}
+
 // aes gcm encryption
 func (gcm *GCM) Assert(key [16]frontend.Variable, iv [12]frontend.Variable, chunkIndex frontend.Variable, plaintext, ciphertext []frontend.Variable) {
 
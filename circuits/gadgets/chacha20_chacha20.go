@@ -0,0 +1,169 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// evaluate chacha20 keystream encryption
+type ChaCha20Wrapper struct {
+	Key        [32]frontend.Variable
+	Nonce      [12]frontend.Variable `gnark:",public"`
+	Counter    frontend.Variable     `gnark:",public"`
+	Plaintext  []frontend.Variable
+	Ciphertext []frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *ChaCha20Wrapper) Define(api frontend.API) error {
+
+	chacha := NewChaCha20(api)
+	chacha.Assert(circuit.Key[:], circuit.Nonce, circuit.Counter, circuit.Plaintext, circuit.Ciphertext)
+
+	return nil
+}
+
+// "expand 32-byte k"
+var chachaConstants = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+// chacha20 stream cipher of rfc 8439 on the uint32api
+type ChaCha20 struct {
+	api frontend.API
+	u32 *uint32api
+	u8  *uint8api
+}
+
+func NewChaCha20(api frontend.API) ChaCha20 {
+	return ChaCha20{api: api, u32: newUint32API(api), u8: newUint8API(api)}
+}
+
+// Assert checks ciphertext = plaintext xor keystream, the keystream
+// starts at block counter and each 64 byte block increments the counter
+func (c *ChaCha20) Assert(key []frontend.Variable, nonce [12]frontend.Variable, counter frontend.Variable, plaintext, ciphertext []frontend.Variable) {
+
+	keyStream := c.KeyStream(key, nonce, counter, len(plaintext))
+
+	for i := 0; i < len(plaintext); i++ {
+		p := c.u8.asUint8(plaintext[i])
+		x := c.u8.xor(p, keyStream[i])
+		c.api.AssertIsEqual(c.u8.fromUint8(x), ciphertext[i])
+	}
+}
+
+// KeyStream returns length keystream bytes starting at block counter
+func (c *ChaCha20) KeyStream(key []frontend.Variable, nonce [12]frontend.Variable, counter frontend.Variable, length int) []xuint8 {
+
+	// key and nonce words are reused for all blocks
+	var keyWords [8]xuint32
+	for i := 0; i < 8; i++ {
+		keyWords[i] = c.leWord(key[i*4 : i*4+4])
+	}
+	var nonceWords [3]xuint32
+	for i := 0; i < 3; i++ {
+		nonceWords[i] = c.leWord(nonce[i*4 : i*4+4])
+	}
+
+	numberBlocks := (length + 63) / 64
+	var out []xuint8
+	for epoch := 0; epoch < numberBlocks; epoch++ {
+		// the 32 bit decomposition bounds the counter, it must not wrap around
+		counterWord := c.u32.asUint32(c.api.Add(counter, epoch))
+		block := c.block(keyWords, counterWord, nonceWords)
+		out = append(out, block[:]...)
+	}
+
+	return out[:length]
+}
+
+// Block returns the 64 byte output of the block function
+func (c *ChaCha20) Block(key [32]frontend.Variable, counter frontend.Variable, nonce [12]frontend.Variable) [64]frontend.Variable {
+
+	var keyWords [8]xuint32
+	for i := 0; i < 8; i++ {
+		keyWords[i] = c.leWord(key[i*4 : i*4+4])
+	}
+	var nonceWords [3]xuint32
+	for i := 0; i < 3; i++ {
+		nonceWords[i] = c.leWord(nonce[i*4 : i*4+4])
+	}
+
+	block := c.block(keyWords, c.u32.asUint32(counter), nonceWords)
+
+	var out [64]frontend.Variable
+	for i := 0; i < 64; i++ {
+		out[i] = c.u8.fromUint8(block[i])
+	}
+	return out
+}
+
+func (c *ChaCha20) block(key [8]xuint32, counter xuint32, nonce [3]xuint32) [64]xuint8 {
+
+	// initial state, constants | key | counter | nonce
+	var init [16]xuint32
+	for i := 0; i < 4; i++ {
+		init[i] = constUint32(chachaConstants[i])
+	}
+	copy(init[4:12], key[:])
+	init[12] = counter
+	copy(init[13:16], nonce[:])
+
+	x := init
+	for round := 0; round < 10; round++ {
+		// column rounds
+		c.quarterRound(&x, 0, 4, 8, 12)
+		c.quarterRound(&x, 1, 5, 9, 13)
+		c.quarterRound(&x, 2, 6, 10, 14)
+		c.quarterRound(&x, 3, 7, 11, 15)
+		// diagonal rounds
+		c.quarterRound(&x, 0, 5, 10, 15)
+		c.quarterRound(&x, 1, 6, 11, 12)
+		c.quarterRound(&x, 2, 7, 8, 13)
+		c.quarterRound(&x, 3, 4, 9, 14)
+	}
+
+	// add initial state and serialize little endian
+	var out [64]xuint8
+	for i := 0; i < 16; i++ {
+		w := c.u32.add(x[i], init[i])
+		for j := 0; j < 4; j++ {
+			copy(out[i*4+j][:], w[j*8:j*8+8])
+		}
+	}
+	return out
+}
+
+func (c *ChaCha20) quarterRound(x *[16]xuint32, a, b, cc, d int) {
+	x[a] = c.u32.add(x[a], x[b])
+	x[d] = c.u32.lrot(c.u32.xor(x[d], x[a]), 16)
+	x[cc] = c.u32.add(x[cc], x[d])
+	x[b] = c.u32.lrot(c.u32.xor(x[b], x[cc]), 12)
+	x[a] = c.u32.add(x[a], x[b])
+	x[d] = c.u32.lrot(c.u32.xor(x[d], x[a]), 8)
+	x[cc] = c.u32.add(x[cc], x[d])
+	x[b] = c.u32.lrot(c.u32.xor(x[b], x[cc]), 7)
+}
+
+// little endian word of four bytes
+func (c *ChaCha20) leWord(in []frontend.Variable) xuint32 {
+	var res xuint32
+	for j := 0; j < 4; j++ {
+		b := c.u8.asUint8(in[j])
+		copy(res[j*8:j*8+8], b[:])
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/chacha20"
+)
+
+func TestChaCha20(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	key := mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce := mustHex("000000000000004a00000000")
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+
+	// rfc 8439 section 2.4.2 encryption starting at block counter 1
+	cipher, err := chacha20.NewUnauthenticatedCipher(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher.SetCounter(1)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.XORKeyStream(ciphertext, plaintext)
+
+	newCircuit := func() *ChaCha20Wrapper {
+		return &ChaCha20Wrapper{
+			Plaintext:  make([]frontend.Variable, len(plaintext)),
+			Ciphertext: make([]frontend.Variable, len(ciphertext)),
+		}
+	}
+
+	assignment := newCircuit()
+	assignment.Counter = 1
+	for i := 0; i < 32; i++ {
+		assignment.Key[i] = key[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.Nonce[i] = nonce[i]
+	}
+	for i := 0; i < len(plaintext); i++ {
+		assignment.Plaintext[i] = plaintext[i]
+		assignment.Ciphertext[i] = ciphertext[i]
+	}
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// wrong block counter must fail
+	assignment.Counter = 2
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestRecordChaCha(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	plaintext := recordPlaintext + "\x17"
+	aad := []byte{0x17, 0x03, 0x03, 0x00, byte(len(plaintext) + 16)}
+
+	// seal record with go crypto, tag is appended to the ciphertext
+	sealed := recordChaChaAEAD(t).Seal(nil, recordIv, []byte(plaintext), aad)
+	tag := sealed[len(plaintext):]
+
+	record := newRecordCircuit(plaintext, `"price"`, "38002")
+	newCircuit := func() *RecordChaChaWrapper {
+		return &RecordChaChaWrapper{
+			PlainChunks:    make([]frontend.Variable, len(plaintext)),
+			CipherChunks:   make([]frontend.Variable, len(plaintext)),
+			Substring:      make([]frontend.Variable, len(record.Substring)),
+			SubstringStart: record.SubstringStart,
+			SubstringEnd:   record.SubstringEnd,
+			ValueStart:     record.ValueStart,
+			ValueEnd:       record.ValueEnd,
+		}
+	}
+
+	assignment := newCircuit()
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 1
+	assignment.Threshold = 38002
+	assignment.PlainChunks = toVariables([]byte(plaintext))
+	assignment.CipherChunks = toVariables(sealed[:len(plaintext)])
+	assignment.Substring = toVariables([]byte(`"price"`))
+	for i := 0; i < 32; i++ {
+		assignment.Key[i] = recordChaChaKey[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = recordIv[i]
+	}
+	for i := 0; i < 16; i++ {
+		assignment.Tag[i] = tag[i]
+	}
+	for i := 0; i < 5; i++ {
+		assignment.Aad[i] = aad[i]
+	}
+
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// modified tag must fail
+	assignment.Tag[15] = tag[15] ^ 0x01
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
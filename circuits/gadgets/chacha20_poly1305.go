@@ -0,0 +1,171 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// evaluate chacha20-poly1305 encryption and tag of a full record
+type ChaCha20Poly1305Wrapper struct {
+	Key        [32]frontend.Variable
+	Nonce      [12]frontend.Variable `gnark:",public"`
+	Plaintext  []frontend.Variable
+	Ciphertext []frontend.Variable   `gnark:",public"`
+	Aad        []frontend.Variable   `gnark:",public"`
+	Tag        [16]frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *ChaCha20Poly1305Wrapper) Define(api frontend.API) error {
+
+	aead := NewChaCha20Poly1305(api)
+	aead.Assert(circuit.Key[:], circuit.Nonce, 1, circuit.Plaintext, circuit.Ciphertext)
+	aead.AssertTag(circuit.Key[:], circuit.Nonce, circuit.Aad, circuit.Ciphertext, circuit.Tag)
+
+	return nil
+}
+
+// poly1305 prime 2^130 - 5 as emulated field
+type poly1305Fp struct{}
+
+var poly1305Modulus = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 130), big.NewInt(5))
+
+func (fp poly1305Fp) NbLimbs() uint     { return 3 }
+func (fp poly1305Fp) BitsPerLimb() uint { return 64 }
+func (fp poly1305Fp) IsPrime() bool     { return true }
+func (fp poly1305Fp) Modulus() *big.Int { return poly1305Modulus }
+
+// poly1305 one-time authenticator of rfc 8439, the polynomial is
+// evaluated with emulated arithmetic modulo 2^130 - 5
+type Poly1305 struct {
+	api   frontend.API
+	field *emulated.Field[poly1305Fp]
+}
+
+func NewPoly1305(api frontend.API) Poly1305 {
+	field, err := emulated.NewField[poly1305Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	return Poly1305{api: api, field: field}
+}
+
+// Sum returns the tag of msg under the one-time key r || s
+func (p *Poly1305) Sum(key [32]frontend.Variable, msg []frontend.Variable) [16]frontend.Variable {
+
+	// clamp r, clears the top four bits of bytes 3, 7, 11, 15
+	// and the bottom two bits of bytes 4, 8, 12
+	var rBits []frontend.Variable
+	for i := 0; i < 16; i++ {
+		b := p.api.ToBinary(key[i], 8)
+		for j := 0; j < 8; j++ {
+			if (i%4 == 3 && j >= 4) || (i%4 == 0 && i > 0 && j < 2) {
+				b[j] = 0
+			}
+		}
+		rBits = append(rBits, b...)
+	}
+	r := p.field.FromBits(rBits...)
+
+	// acc = (acc + block) * r for every 16 byte block, each block
+	// is extended by a 0x01 byte above its most significant byte
+	acc := p.field.Zero()
+	for start := 0; start < len(msg); start += 16 {
+		end := min(start+16, len(msg))
+		var blockBits []frontend.Variable
+		for i := start; i < end; i++ {
+			blockBits = append(blockBits, p.api.ToBinary(msg[i], 8)...)
+		}
+		blockBits = append(blockBits, 1)
+		block := p.field.FromBits(blockBits...)
+		acc = p.field.Mul(p.field.Add(acc, block), r)
+	}
+
+	// canonical accumulator, tag = (acc + s) mod 2^128
+	acc = p.field.Reduce(acc)
+	p.field.AssertIsInRange(acc)
+	accBits := p.field.ToBits(acc)
+
+	var sBits []frontend.Variable
+	for i := 16; i < 32; i++ {
+		sBits = append(sBits, p.api.ToBinary(key[i], 8)...)
+	}
+	sum := p.api.Add(p.api.FromBinary(accBits[:128]...), p.api.FromBinary(sBits...))
+	sumBits := p.api.ToBinary(sum, 129)
+
+	var tag [16]frontend.Variable
+	for i := 0; i < 16; i++ {
+		tag[i] = p.api.FromBinary(sumBits[i*8 : i*8+8]...)
+	}
+	return tag
+}
+
+// chacha20-poly1305 aead of rfc 8439
+type ChaCha20Poly1305 struct {
+	api      frontend.API
+	chacha   ChaCha20
+	poly1305 Poly1305
+}
+
+func NewChaCha20Poly1305(api frontend.API) ChaCha20Poly1305 {
+	return ChaCha20Poly1305{api: api, chacha: NewChaCha20(api), poly1305: NewPoly1305(api)}
+}
+
+// Assert checks the encryption of plaintext, the record keystream starts at counter 1
+func (aead *ChaCha20Poly1305) Assert(key []frontend.Variable, nonce [12]frontend.Variable, counter frontend.Variable, plaintext, ciphertext []frontend.Variable) {
+	aead.chacha.Assert(key, nonce, counter, plaintext, ciphertext)
+}
+
+// AssertTag checks the tag over aad and the full ciphertext
+func (aead *ChaCha20Poly1305) AssertTag(key []frontend.Variable, nonce [12]frontend.Variable, aad, ciphertext []frontend.Variable, tag [16]frontend.Variable) {
+
+	// one-time key, first 32 bytes of keystream block 0
+	keyStream := aead.chacha.KeyStream(key, nonce, 0, 32)
+	var polyKey [32]frontend.Variable
+	for i := 0; i < 32; i++ {
+		polyKey[i] = aead.chacha.u8.fromUint8(keyStream[i])
+	}
+
+	// aad || pad16 || ciphertext || pad16 || le64(len(aad)) || le64(len(ciphertext))
+	var macData []frontend.Variable
+	macData = append(macData, aad...)
+	macData = append(macData, zeroPad16(len(aad))...)
+	macData = append(macData, ciphertext...)
+	macData = append(macData, zeroPad16(len(ciphertext))...)
+	for _, l := range []int{len(aad), len(ciphertext)} {
+		for i := 0; i < 8; i++ {
+			macData = append(macData, (uint64(l)>>(8*i))&0xff)
+		}
+	}
+
+	computed := aead.poly1305.Sum(polyKey, macData)
+	for i := 0; i < 16; i++ {
+		aead.api.AssertIsEqual(computed[i], tag[i])
+	}
+}
+
+func zeroPad16(length int) []frontend.Variable {
+	pad := make([]frontend.Variable, (16-length%16)%16)
+	for i := range pad {
+		pad[i] = 0
+	}
+	return pad
+}
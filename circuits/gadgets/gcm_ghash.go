@@ -0,0 +1,168 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// ghash gadget computes the universal hash of aes gcm in GF(2^128)
+// with the reduction polynomial x^128 + x^7 + x^2 + x + 1.
+// field elements are represented as 128 bits in gcm bit order,
+// meaning bit 0 is the most significant bit of byte 0.
+type GHash struct {
+	api frontend.API
+}
+
+func NewGHash(api frontend.API) GHash {
+	return GHash{api: api}
+}
+
+// reduction matrix, row m holds the coefficients of x^m mod p(x)
+var ghashReduction = func() [255][128]bool {
+	var out [255][128]bool
+	var v [128]bool
+	v[0] = true
+	for m := 0; m < 255; m++ {
+		out[m] = v
+		// multiply by x
+		carry := v[127]
+		copy(v[1:], v[:127])
+		v[0] = false
+		if carry {
+			v[0] = !v[0]
+			v[1] = !v[1]
+			v[2] = !v[2]
+			v[7] = !v[7]
+		}
+	}
+	return out
+}()
+
+// ToBits converts a 16 byte block into its gcm bit representation
+func (gh *GHash) ToBits(block [16]frontend.Variable) [128]frontend.Variable {
+	var out [128]frontend.Variable
+	for i := 0; i < 16; i++ {
+		b := gh.api.ToBinary(block[i], 8)
+		for j := 0; j < 8; j++ {
+			out[i*8+j] = b[7-j]
+		}
+	}
+	return out
+}
+
+// FromBits converts a gcm bit representation into a 16 byte block
+func (gh *GHash) FromBits(in [128]frontend.Variable) [16]frontend.Variable {
+	var out [16]frontend.Variable
+	for i := 0; i < 16; i++ {
+		var b [8]frontend.Variable
+		for j := 0; j < 8; j++ {
+			b[7-j] = in[i*8+j]
+		}
+		out[i] = gh.api.FromBinary(b[:]...)
+	}
+	return out
+}
+
+// Mul multiplies two field elements. the carry-less product is computed
+// as integer sums of bit products, the reduction is folded into the sums
+// and each output bit is the parity of its sum.
+func (gh *GHash) Mul(x, y [128]frontend.Variable) [128]frontend.Variable {
+
+	// coefficients of the unreduced product, c[m] <= 128
+	var c [255]frontend.Variable
+	for m := 0; m < 255; m++ {
+		c[m] = 0
+	}
+	for i := 0; i < 128; i++ {
+		for j := 0; j < 128; j++ {
+			c[i+j] = gh.api.Add(c[i+j], gh.api.Mul(x[i], y[j]))
+		}
+	}
+
+	var out [128]frontend.Variable
+	for k := 0; k < 128; k++ {
+		sum := frontend.Variable(0)
+		bound := 0
+		for m := 0; m < 255; m++ {
+			if ghashReduction[m][k] {
+				sum = gh.api.Add(sum, c[m])
+				bound += min(m+1, 255-m)
+			}
+		}
+		// least significant bit is the parity of the sum
+		out[k] = gh.api.ToBinary(sum, bits.Len(uint(bound)))[0]
+	}
+
+	return out
+}
+
+// Update absorbs zero padded blocks of in into the state x
+func (gh *GHash) Update(hBits, x [128]frontend.Variable, in []frontend.Variable) [128]frontend.Variable {
+
+	numberBlocks := (len(in) + 15) / 16
+	for epoch := 0; epoch < numberBlocks; epoch++ {
+
+		var block [16]frontend.Variable
+		for i := 0; i < 16; i++ {
+			if epoch*16+i < len(in) {
+				block[i] = in[epoch*16+i]
+			} else {
+				block[i] = 0
+			}
+		}
+
+		blockBits := gh.ToBits(block)
+		for i := 0; i < 128; i++ {
+			x[i] = gh.api.Xor(x[i], blockBits[i])
+		}
+		x = gh.Mul(x, hBits)
+	}
+
+	return x
+}
+
+// Hash computes GHASH_H(aad, ciphertext) including the length block
+func (gh *GHash) Hash(h [16]frontend.Variable, aad, ciphertext []frontend.Variable) [16]frontend.Variable {
+
+	hBits := gh.ToBits(h)
+
+	x := gh.Update(hBits, gh.zeroBits(), aad)
+	x = gh.Update(hBits, x, ciphertext)
+
+	// bit lengths of aad and ciphertext as 64 bit big endian integers
+	var lengths [16]frontend.Variable
+	aadBits := uint64(len(aad)) * 8
+	ctBits := uint64(len(ciphertext)) * 8
+	for i := 0; i < 8; i++ {
+		lengths[i] = (aadBits >> (56 - 8*i)) & 0xff
+		lengths[8+i] = (ctBits >> (56 - 8*i)) & 0xff
+	}
+	x = gh.Update(hBits, x, lengths[:])
+
+	return gh.FromBits(x)
+}
+
+func (gh *GHash) zeroBits() [128]frontend.Variable {
+	var out [128]frontend.Variable
+	for i := 0; i < 128; i++ {
+		out[i] = 0
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordTag(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	plaintext := recordPlaintext + "\x17"
+	aad := []byte{0x17, 0x03, 0x03, 0x00, byte(len(plaintext) + 16)}
+
+	// seal record with go crypto, tag is appended to the ciphertext
+	sealed := recordAEAD(t).Seal(nil, recordIv, []byte(plaintext), aad)
+	tag := sealed[len(plaintext):]
+
+	newCircuit := func() *RecordTagWrapper {
+		return &RecordTagWrapper{RecordWrapper: *newRecordCircuit(plaintext, `"price"`, "38002")}
+	}
+
+	assignment := &RecordTagWrapper{RecordWrapper: *newRecordAssignment(t, plaintext, `"price"`, "38002", 38002)}
+	for i := 0; i < 16; i++ {
+		assignment.Tag[i] = tag[i]
+	}
+	for i := 0; i < 5; i++ {
+		assignment.Aad[i] = aad[i]
+	}
+
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// modified tag must fail
+	assignment.Tag[0] = tag[0] ^ 0x01
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
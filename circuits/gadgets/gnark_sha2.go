@@ -18,16 +18,17 @@ var _seed = uints.NewU32Array([]uint32{
 })
 
 type sha2digest struct {
+	api  frontend.API
 	uapi *uints.BinaryField[uints.U32]
 	in   []uints.U8
 }
 
-func NewSha2(api frontend.API) (hash.BinaryHasher, error) {
+func NewSha2(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
 	uapi, err := uints.New[uints.U32](api)
 	if err != nil {
 		return nil, err
 	}
-	return &sha2digest{uapi: uapi}, nil
+	return &sha2digest{api: api, uapi: uapi}, nil
 }
 
 func (d *sha2digest) Write(data []uints.U8) {
@@ -70,16 +71,86 @@ func (d *sha2digest) Sum() []uints.U8 {
 	return ret
 }
 
+// FixedLengthSum hashes the first length bytes of the written data. The
+// written data is the maximum buffer, length is an in-circuit value in
+// [0, len(buffer)]. The padding byte is placed at position length and the
+// bit length at the end of the block holding it, the digest of that block is
+// selected from the running digests of all blocks.
 func (d *sha2digest) FixedLengthSum(length frontend.Variable) []uints.U8 {
-	panic("TODO")
-	// we need to do two things here -- first the padding has to be put to the
-	// right place. For that we need to know how many blocks we have used. We
-	// need to fit at least 9 more bytes (padding byte and 8 bytes for input
-	// length). Knowing the block, we have to keep running track if the current
-	// block is the expected one.
-	//
-	// idea - have a mask for blocks where 1 is only for the block we want to
-	// use.
+
+	maxLen := len(d.in)
+	nbBlocks := (maxLen + 9 + 63) / 64
+
+	// eq[i] is 1 at position length, past[i] is 1 for positions >= length
+	eq := make([]frontend.Variable, nbBlocks*64)
+	past := make([]frontend.Variable, nbBlocks*64)
+	var acc frontend.Variable = 0
+	for i := range eq {
+		if i <= maxLen {
+			eq[i] = d.api.IsZero(d.api.Sub(length, i))
+		} else {
+			eq[i] = 0
+		}
+		acc = d.api.Add(acc, eq[i])
+		past[i] = acc
+	}
+	// length must not exceed the buffer
+	d.api.AssertIsEqual(acc, 1)
+
+	// block k is the final block if the padding byte and the 8 length bytes
+	// fit into it, meaning length is in [64k-8, 64k+55]
+	sel := make([]frontend.Variable, nbBlocks)
+	for k := range sel {
+		sel[k] = 0
+		for i := max(64*k-8, 0); i <= min(64*k+55, maxLen); i++ {
+			sel[k] = d.api.Add(sel[k], eq[i])
+		}
+	}
+
+	// big endian bit length
+	lenBits := d.api.ToBinary(d.api.Mul(length, 8), 64)
+	var lenBytes [8]frontend.Variable
+	for j := 0; j < 8; j++ {
+		lenBytes[j] = d.api.FromBinary(lenBits[(7-j)*8 : (7-j)*8+8]...)
+	}
+
+	var runningDigest [8]uints.U32
+	var buf [64]uints.U8
+	copy(runningDigest[:], _seed)
+	var digest [32]frontend.Variable
+	for i := range digest {
+		digest[i] = 0
+	}
+	for k := 0; k < nbBlocks; k++ {
+		for j := 0; j < 64; j++ {
+			i := k*64 + j
+			// message byte before length, padding byte at length, zero after
+			v := d.api.Mul(eq[i], 0x80)
+			if i < maxLen {
+				v = d.api.Add(v, d.api.Mul(d.in[i].Val, d.api.Sub(1, past[i])))
+			}
+			// bit length at the end of the final block
+			if j >= 56 {
+				v = d.api.Add(v, d.api.Mul(sel[k], lenBytes[j-56]))
+			}
+			buf[j] = d.uapi.ByteValueOf(v)
+		}
+		runningDigest = sha2.Permute(d.uapi, runningDigest, buf)
+
+		// keep the digest of the final block
+		for w := range runningDigest {
+			bts := d.uapi.UnpackMSB(runningDigest[w])
+			for b := range bts {
+				digest[w*4+b] = d.api.Add(digest[w*4+b], d.api.Mul(sel[k], bts[b].Val))
+			}
+		}
+	}
+
+	ret := make([]uints.U8, 32)
+	for i := range ret {
+		ret[i] = d.uapi.ByteValueOf(digest[i])
+	}
+	return ret
 }
 
 func (d *sha2digest) Reset() {
@@ -0,0 +1,48 @@
+package gadgets
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+func TestSha2FixedLengthSum(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// 130 byte buffer spans three blocks, lengths cover the block
+	// boundaries of the padding byte and the length field
+	maxLen := 130
+	buffer := make([]byte, maxLen)
+	for i := range buffer {
+		buffer[i] = byte(i*13 + 5)
+	}
+
+	for _, length := range []int{0, 55, 56, 64, 120, 130} {
+		dgst := sha256.Sum256(buffer[:length])
+
+		assignment := Sha2FixedLengthWrapper{
+			In:       uints.NewU8Array(buffer),
+			Length:   length,
+			Expected: [32]uints.U8(uints.NewU8Array(dgst[:])),
+		}
+		circuit := Sha2FixedLengthWrapper{In: make([]uints.U8, maxLen)}
+		assert.SolvingSucceeded(&circuit, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+
+	// digest of a different length must fail
+	dgst := sha256.Sum256(buffer[:64])
+	assignment := Sha2FixedLengthWrapper{
+		In:       uints.NewU8Array(buffer),
+		Length:   63,
+		Expected: [32]uints.U8(uints.NewU8Array(dgst[:])),
+	}
+	assert.SolvingFailed(&Sha2FixedLengthWrapper{In: make([]uints.U8, maxLen)}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// length beyond the buffer must fail
+	assignment.Length = maxLen + 1
+	assert.SolvingFailed(&Sha2FixedLengthWrapper{In: make([]uints.U8, maxLen)}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
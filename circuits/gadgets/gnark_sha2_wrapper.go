@@ -35,3 +35,33 @@ func (c *Sha2Wrapper) Define(api frontend.API) error {
 	}
 	return nil
 }
+
+// variable length pre-image knowledge proof, the first Length bytes of In
+// hash to the public digest
+type Sha2FixedLengthWrapper struct {
+	In       []uints.U8
+	Length   frontend.Variable
+	Expected [32]uints.U8 `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (c *Sha2FixedLengthWrapper) Define(api frontend.API) error {
+
+	h, err := NewSha2(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.FixedLengthSum(c.Length)
+	if len(res) != 32 {
+		return fmt.Errorf("not 32 bytes")
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
@@ -35,7 +35,6 @@ func (circuit *LookUpAES256Wrapper) Define(api frontend.API) error {
 			api.AssertIsEqual(circuit.Ciphertext[b*16+i], aes.VariableXor(keystream[i], circuit.Plaintext[b*16+i], 8))
 		}
 		counter = api.Add(counter, 1)
-		// api.AssertIsLessOrEqual(counter, math.MaxUint32)
 	}
 
 	// api.AssertIsEqual(counter, api.Add(circuit.Counter, BLOCKS))
@@ -113,6 +113,7 @@ func (aes *LookUpAESGadget) Subws(sbox *logderivlookup.Table, a ...frontend.Vari
 	return sbox.Lookup(a...)
 }
 
+// 32 bit decomposition of the counter, a block counter above 2^32-1 is unsatisfiable
 func (aes *LookUpAESGadget) createIV(counter frontend.Variable, iv []frontend.Variable) {
 	aBits := aes.api.ToBinary(counter, 32)
 
@@ -0,0 +1,131 @@
+/*
+MIT License
+
+Copyright (c) Jan Lauinger, 2023 zkCollective, Celer Network
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gadgets
+
+import (
+	"crypto/sha512"
+	"encoding"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// input lengths cover empty input and both padding cases around the 112 byte boundary
+var sha512TestLengths = []int{0, 3, 111, 112, 128, 200}
+
+func sha512TestInput(n int) []byte {
+	in := make([]byte, n)
+	for i := range in {
+		in[i] = byte(i*7 + 1)
+	}
+	return in
+}
+
+func TestSha384(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, n := range sha512TestLengths {
+		in := sha512TestInput(n)
+		sum := sha512.Sum384(in)
+
+		assignment := Sha384Wrapper{In: make([]frontend.Variable, n)}
+		for i := 0; i < n; i++ {
+			assignment.In[i] = in[i]
+		}
+		for i := 0; i < 48; i++ {
+			assignment.Hash[i] = sum[i]
+		}
+
+		assert.SolvingSucceeded(&Sha384Wrapper{In: make([]frontend.Variable, n)}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+
+	// wrong digest must fail
+	in := sha512TestInput(3)
+	sum := sha512.Sum384(in)
+	sum[47] ^= 1
+	assignment := Sha384Wrapper{In: make([]frontend.Variable, 3)}
+	for i := 0; i < 3; i++ {
+		assignment.In[i] = in[i]
+	}
+	for i := 0; i < 48; i++ {
+		assignment.Hash[i] = sum[i]
+	}
+	assert.SolvingFailed(&Sha384Wrapper{In: make([]frontend.Variable, 3)}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestSha512(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, n := range sha512TestLengths {
+		in := sha512TestInput(n)
+		sum := sha512.Sum512(in)
+
+		assignment := Sha512Wrapper{In: make([]frontend.Variable, n)}
+		for i := 0; i < n; i++ {
+			assignment.In[i] = in[i]
+		}
+		for i := 0; i < 64; i++ {
+			assignment.Hash[i] = sum[i]
+		}
+
+		assert.SolvingSucceeded(&Sha512Wrapper{In: make([]frontend.Variable, n)}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestSha384WithIV(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := sha512TestInput(200)
+	sum := sha512.Sum384(in)
+
+	// intermediate state after the first block, the marshaled state
+	// starts with a 4 byte magic followed by the eight state words
+	h := sha512.New384()
+	h.Write(in[:128])
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCircuit := func() *Sha384IVWrapper {
+		return &Sha384IVWrapper{Length: 128, In: make([]frontend.Variable, len(in)-128)}
+	}
+
+	assignment := newCircuit()
+	for i := 0; i < 64; i++ {
+		assignment.IV[i] = state[4+i]
+	}
+	for i := 128; i < len(in); i++ {
+		assignment.In[i-128] = in[i]
+	}
+	for i := 0; i < 48; i++ {
+		assignment.Hash[i] = sum[i]
+	}
+
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
@@ -36,7 +36,7 @@ func (circuit *AuthTagWrapper) Define(api frontend.API) error {
 
 	// type conversion
 	tag.SetParams(
-		circuit.Key,
+		circuit.Key[:],
 		circuit.IvCounter,
 		circuit.Zeros,
 		circuit.ECB1,
@@ -44,25 +44,24 @@ func (circuit *AuthTagWrapper) Define(api frontend.API) error {
 	)
 
 	// verify tag
-	tag.Assert()
-
-	return nil
+	return tag.Assert()
 }
 
 type Tls13AuthTag struct {
-	api       frontend.API
-	Key       [16]frontend.Variable
-	IvCounter [16]frontend.Variable // `gnark:",public"`
-	Zeros     [16]frontend.Variable // `gnark:",public"`
-	ECB1      [16]frontend.Variable // `gnark:",public"`
-	ECB0      [16]frontend.Variable // `gnark:",public"`
+	api         frontend.API
+	CipherSuite CipherSuite
+	Key         []frontend.Variable
+	IvCounter   [16]frontend.Variable // `gnark:",public"`
+	Zeros       [16]frontend.Variable // `gnark:",public"`
+	ECB1        [16]frontend.Variable // `gnark:",public"`
+	ECB0        [16]frontend.Variable // `gnark:",public"`
 }
 
 func NewTls13AuthTag(api frontend.API) Tls13AuthTag {
 	return Tls13AuthTag{api: api}
 }
 
-func (circuit *Tls13AuthTag) SetParams(key, ivCounter, zeros, ecb1, ecb0 [16]frontend.Variable) {
+func (circuit *Tls13AuthTag) SetParams(key []frontend.Variable, ivCounter, zeros, ecb1, ecb0 [16]frontend.Variable) {
 	circuit.Key = key
 	circuit.IvCounter = ivCounter
 	circuit.Zeros = zeros
@@ -70,15 +69,25 @@ func (circuit *Tls13AuthTag) SetParams(key, ivCounter, zeros, ecb1, ecb0 [16]fro
 	circuit.ECB0 = ecb0
 }
 
+// defaults to TLS_AES_128_GCM_SHA256, key length must match the cipher suite
+func (circuit *Tls13AuthTag) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
 // Define declares the circuit's constraints
 func (circuit *Tls13AuthTag) Assert() error {
 
+	// the tag gadget is aes only
+	if err := circuit.CipherSuite.requireAESGCM(); err != nil {
+		return err
+	}
+
 	// aes circuit
 	// aes := NewAES128(circuit.api) // for groth16
-	aes := NewLookUpAES128(circuit.api) // for lookup plonk
+	aes := circuit.CipherSuite.newAES(circuit.api) // for lookup plonk
 
 	// encrypt zeros
-	ecb0 := aes.Encrypt(circuit.Key[:], circuit.Zeros)
+	ecb0 := aes.Encrypt(circuit.Key, circuit.Zeros)
 
 	// constraint check
 	for i := 0; i < len(circuit.ECB0); i++ {
@@ -86,7 +95,7 @@ func (circuit *Tls13AuthTag) Assert() error {
 	}
 
 	// encrypt iv||counter=0
-	ecb1 := aes.Encrypt(circuit.Key[:], circuit.IvCounter)
+	ecb1 := aes.Encrypt(circuit.Key, circuit.IvCounter)
 
 	// constraints check
 	for i := 0; i < len(circuit.ECB1); i++ {
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// tls13 cipher suites supported by the circuits
+type CipherSuite int
+
+const (
+	TLS_AES_128_GCM_SHA256 CipherSuite = iota
+	TLS_AES_256_GCM_SHA384
+	TLS_CHACHA20_POLY1305_SHA256
+)
+
+func ParseCipherSuite(name string) (CipherSuite, error) {
+	switch name {
+	case "TLS_AES_128_GCM_SHA256":
+		return TLS_AES_128_GCM_SHA256, nil
+	case "TLS_AES_256_GCM_SHA384":
+		return TLS_AES_256_GCM_SHA384, nil
+	case "TLS_CHACHA20_POLY1305_SHA256":
+		return TLS_CHACHA20_POLY1305_SHA256, nil
+	}
+	return TLS_AES_128_GCM_SHA256, errors.New("unsupported cipher suite " + name)
+}
+
+func (cs CipherSuite) String() string {
+	switch cs {
+	case TLS_AES_256_GCM_SHA384:
+		return "TLS_AES_256_GCM_SHA384"
+	case TLS_CHACHA20_POLY1305_SHA256:
+		return "TLS_CHACHA20_POLY1305_SHA256"
+	}
+	return "TLS_AES_128_GCM_SHA256"
+}
+
+// aes gcm suites, chacha20-poly1305 records use the chacha20 gadgets
+func (cs CipherSuite) IsAESGCM() bool {
+	return cs != TLS_CHACHA20_POLY1305_SHA256
+}
+
+// error for circuits built on the aes gadgets
+func (cs CipherSuite) requireAESGCM() error {
+	if !cs.IsAESGCM() {
+		return errors.New("unsupported cipher suite " + cs.String())
+	}
+	return nil
+}
+
+// traffic key length in bytes
+func (cs CipherSuite) KeyLen() int {
+	if cs == TLS_AES_128_GCM_SHA256 {
+		return 16
+	}
+	return 32
+}
+
+// hash output length in bytes
+func (cs CipherSuite) HashLen() int {
+	if cs == TLS_AES_256_GCM_SHA384 {
+		return 48
+	}
+	return 32
+}
+
+// hash block length in bytes, also the length of hmac padded keys
+func (cs CipherSuite) BlockLen() int {
+	if cs == TLS_AES_256_GCM_SHA384 {
+		return 128
+	}
+	return 64
+}
+
+// hash internal state length in bytes, used for intermediate hashes
+func (cs CipherSuite) StateLen() int {
+	if cs == TLS_AES_256_GCM_SHA384 {
+		return 64
+	}
+	return 32
+}
+
+// aes gadget of the cipher suite
+func (cs CipherSuite) newAES(api frontend.API) LookUpAES {
+	if !cs.IsAESGCM() {
+		panic("no aes gadget for cipher suite " + cs.String())
+	}
+	if cs == TLS_AES_256_GCM_SHA384 {
+		aes := NewLookUpAES256(api)
+		return &aes
+	}
+	aes := NewLookUpAES128(api)
+	return &aes
+}
+
+// aes gcm gadget of the cipher suite
+func (cs CipherSuite) newGCM(api frontend.API) GCM2 {
+	return GCM2{api: api, aes: cs.newAES(api)}
+}
+
+// hash of in with the hash function of the cipher suite
+func (cs CipherSuite) sum(api frontend.API, in []frontend.Variable) []frontend.Variable {
+	if cs == TLS_AES_256_GCM_SHA384 {
+		sha := NewSHA384(api)
+		sha.Write(in)
+		return sha.Sum()
+	}
+	sha := NewSHA256(api)
+	sha.Write(in)
+	sum := sha.Sum()
+	return sum[:]
+}
+
+// hash continued from an intermediate state after one block, in must be padded
+func (cs CipherSuite) sumWithIV(api frontend.API, iv, in []frontend.Variable) []frontend.Variable {
+	if cs == TLS_AES_256_GCM_SHA384 {
+		var iv64 [64]frontend.Variable
+		copy(iv64[:], iv)
+		sha := NewSHA384WithIV(api, iv64, 128)
+		return sha.WriteReturn(in)
+	}
+	var iv32 [32]frontend.Variable
+	copy(iv32[:], iv)
+	sha := NewSHA256WithIV(api, iv32, 64)
+	sum := sha.WriteReturn(in)
+	return sum[:]
+}
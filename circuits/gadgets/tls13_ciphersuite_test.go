@@ -0,0 +1,181 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// kdc with slice inputs, sizes depend on the cipher suite
+type kdcSuiteWrapper struct {
+	CipherSuite            CipherSuite
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable   `gnark:",public"`
+	MSin                   []frontend.Variable   `gnark:",public"`
+	XATSin                 []frontend.Variable   `gnark:",public"`
+	TkXAPPin               []frontend.Variable   `gnark:",public"`
+	IvXAPPin               []frontend.Variable   `gnark:",public"`
+	TkXAPP                 []frontend.Variable   `gnark:",public"`
+	IvXAPP                 [12]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *kdcSuiteWrapper) Define(api frontend.API) error {
+
+	tls13_kdc := NewTls13Kdc(api)
+	tls13_kdc.SetCipherSuite(circuit.CipherSuite)
+	tls13_kdc.SetParams(
+		circuit.IntermediateHashHSopad,
+		circuit.MSin,
+		circuit.XATSin,
+		circuit.TkXAPPin,
+		circuit.IvXAPPin,
+		circuit.DHSin,
+	)
+	tk, iv, err := tls13_kdc.Derive()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(circuit.TkXAPP); i++ {
+		api.AssertIsEqual(tk[i], circuit.TkXAPP[i])
+	}
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(iv[i], circuit.IvXAPP[i])
+	}
+
+	return nil
+}
+
+func TestParseCipherSuite(t *testing.T) {
+	for _, cs := range []CipherSuite{TLS_AES_128_GCM_SHA256, TLS_AES_256_GCM_SHA384, TLS_CHACHA20_POLY1305_SHA256} {
+		parsed, err := ParseCipherSuite(cs.String())
+		if err != nil || parsed != cs {
+			t.Fatalf("ParseCipherSuite(%q) = %v, %v", cs.String(), parsed, err)
+		}
+	}
+	if _, err := ParseCipherSuite("TLS_AES_128_CCM_SHA256"); err == nil {
+		t.Fatal("expected error for unsupported cipher suite")
+	}
+}
+
+// authtag with a selectable cipher suite
+type authTagSuiteWrapper struct {
+	CipherSuite CipherSuite
+	Key         []frontend.Variable
+	IvCounter   [16]frontend.Variable `gnark:",public"`
+	Zeros       [16]frontend.Variable `gnark:",public"`
+	ECB1        [16]frontend.Variable `gnark:",public"`
+	ECB0        [16]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *authTagSuiteWrapper) Define(api frontend.API) error {
+	tag := NewTls13AuthTag(api)
+	tag.SetCipherSuite(circuit.CipherSuite)
+	tag.SetParams(circuit.Key, circuit.IvCounter, circuit.Zeros, circuit.ECB1, circuit.ECB0)
+	return tag.Assert()
+}
+
+func TestAuthTagChaCha(t *testing.T) {
+	// the aes authtag rejects chacha20-poly1305 instead of panicking
+	circuit := authTagSuiteWrapper{CipherSuite: TLS_CHACHA20_POLY1305_SHA256, Key: make([]frontend.Variable, 32)}
+	if _, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit); err == nil {
+		t.Fatal("expected error for unsupported cipher suite")
+	}
+}
+
+// kdc from a shared secret with a selectable cipher suite
+type kdcSecretWrapper struct {
+	CipherSuite  CipherSuite
+	SharedSecret []frontend.Variable
+}
+
+func (circuit *kdcSecretWrapper) Define(api frontend.API) error {
+	tls13_kdc := NewTls13Kdc(api)
+	tls13_kdc.SetCipherSuite(circuit.CipherSuite)
+	tls13_kdc.SetSharedSecret(circuit.SharedSecret)
+	_, _, err := tls13_kdc.Derive()
+	return err
+}
+
+func TestKdcSecretSha384(t *testing.T) {
+	// the shared secret key schedule is sha256 only
+	circuit := kdcSecretWrapper{CipherSuite: TLS_AES_256_GCM_SHA384, SharedSecret: make([]frontend.Variable, 32)}
+	if _, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit); err == nil {
+		t.Fatal("expected error for sha384 shared secret key schedule")
+	}
+}
+
+func TestKdcSha384(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	cs := TLS_AES_256_GCM_SHA384
+	intermediateHashHSopad := mustHex("c9dc4a59050490de9ac41a55e524b0aef8d4911eb9bd63f8ee899e08accc492ba2407aa2b31dee1160bd4b8ec40e3cc58032559e5f199bcb47f074af2906e732")
+	dHSin := mustHex("e200c89774b2af02021aaf6af98bc38eabd4602505ee4b87656358a351ed460640c462afd98ae1dcdea1544bbbe5b64d")
+	MSin := mustHex("470dfdef3dbbde75776c9f94bfa6ab77917a77a83da4cdd42709c67849cc7c385b40074e6de1ad269a95c5b870c08812")
+	SATSin := mustHex("9e6e0dc72f3ce34b5e2e4639da6fb04ebad5bf72b4ef122362fd55b53a6057935c07bc96ebec417c6b875ac2405e9449")
+	tkSAPPin := mustHex("33b9958f24f08e6e86139876f66fcf7e6ebca54a145ebc6323aae1d7cc4ab3a6bd76a10684ec3f2ddbf2a9b5a6f1c806")
+	ivSAPPin := mustHex("df8162a6c0bb8cff001ba912355f759b970e05d902f338955a1e84d460f4391618baad5e8d759fe8dc1efb81fb685c25")
+	key := mustHex("6c2a89464fc54f44b5f3dd2b8673e92b36a51855981a7c6560e4b51950cadabd")
+	iv := mustHex("d4d2d46ec5204db1f05be5ba")
+
+	// inner hash input follows the opad block
+	dHSin = append(dHSin, PadSha384(uint64(cs.BlockLen()+len(dHSin)))...)
+
+	newCircuit := func() *kdcSuiteWrapper {
+		return &kdcSuiteWrapper{
+			CipherSuite:            cs,
+			DHSin:                  make([]frontend.Variable, len(dHSin)),
+			IntermediateHashHSopad: make([]frontend.Variable, cs.StateLen()),
+			MSin:                   make([]frontend.Variable, cs.HashLen()),
+			XATSin:                 make([]frontend.Variable, cs.HashLen()),
+			TkXAPPin:               make([]frontend.Variable, cs.HashLen()),
+			IvXAPPin:               make([]frontend.Variable, cs.HashLen()),
+			TkXAPP:                 make([]frontend.Variable, cs.KeyLen()),
+		}
+	}
+
+	assignment := newCircuit()
+	for i := 0; i < len(dHSin); i++ {
+		assignment.DHSin[i] = dHSin[i]
+	}
+	for i := 0; i < cs.StateLen(); i++ {
+		assignment.IntermediateHashHSopad[i] = intermediateHashHSopad[i]
+	}
+	for i := 0; i < cs.HashLen(); i++ {
+		assignment.MSin[i] = MSin[i]
+		assignment.XATSin[i] = SATSin[i]
+		assignment.TkXAPPin[i] = tkSAPPin[i]
+		assignment.IvXAPPin[i] = ivSAPPin[i]
+	}
+	for i := 0; i < cs.KeyLen(); i++ {
+		assignment.TkXAPP[i] = key[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.IvXAPP[i] = iv[i]
+	}
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a wrong traffic key must fail
+	assignment.TkXAPP[31] = key[31] ^ 1
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,441 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/chacha20"
+)
+
+// declarative claim over one tls13 record, e.g.
+//
+//	{
+//	  "cipher_suite": "TLS_AES_128_GCM_SHA256",
+//	  "max_record_size": 48,
+//	  "record": {"key": "..", "iv": "..", "sequence_number": 0, "chunk_index": 2, "plaintext": "..", "ciphertext": ".."},
+//	  "fields": [{"name": "price", "key": "\"price\":\"", "start": 27, "end": 32}],
+//	  "predicates": [{"field": "price", "op": ">", "value": "38000"}],
+//	  "combine": "and",
+//	  "bits": 32,
+//	  "public_result": false
+//	}
+//
+// fields are bound to the key preceding them in the plaintext. numeric
+// predicates compare against value, text predicates (== and !=) against
+// text. fields with public set are revealed as public inputs
+type ClaimSpec struct {
+	CipherSuite   string           `json:"cipher_suite"`
+	MaxRecordSize int              `json:"max_record_size"`
+	Record        ClaimRecord      `json:"record"`
+	Fields        []ClaimField     `json:"fields"`
+	Predicates    []ClaimPredicate `json:"predicates"`
+	Combine       string           `json:"combine"`
+	Bits          int              `json:"bits"`
+	PublicResult  bool             `json:"public_result"`
+}
+
+// record data in hex, the ciphertext starts at block counter chunk_index
+type ClaimRecord struct {
+	Key            string `json:"key"`
+	Iv             string `json:"iv"`
+	SequenceNumber uint64 `json:"sequence_number"`
+	ChunkIndex     uint32 `json:"chunk_index"`
+	Plaintext      string `json:"plaintext"`
+	Ciphertext     string `json:"ciphertext"`
+}
+
+// plaintext[start:end] of the record, key is the text in front of start
+type ClaimField struct {
+	Name   string `json:"name"`
+	Key    string `json:"key"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Public bool   `json:"public"`
+}
+
+type ClaimPredicate struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value string `json:"value,omitempty"`
+	Text  string `json:"text,omitempty"`
+	Not   bool   `json:"not,omitempty"`
+}
+
+func LoadClaimSpec(path string) (*ClaimSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClaimSpec(data)
+}
+
+func ParseClaimSpec(data []byte) (*ClaimSpec, error) {
+	var spec ClaimSpec
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// claim circuit compiled from a spec, the record is padded to the max record size
+type ClaimCircuit struct {
+	Key            []frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	Thresholds     []frontend.Variable   `gnark:",public"`
+	Texts          [][]frontend.Variable `gnark:",public"`
+	Revealed       [][]frontend.Variable `gnark:",public"`
+	Result         []frontend.Variable   `gnark:",public"`
+	CipherSuite    CipherSuite           `gnark:"-"`
+	Fields         []PolicyField         `gnark:"-"`
+	Reveal         []int                 `gnark:"-"`
+	Policy         PolicyNode            `gnark:"-"`
+	Bits           int                   `gnark:"-"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *ClaimCircuit) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+	record.SetCipherSuite(circuit.CipherSuite)
+
+	// insert data, substring, threshold and int offsets are unused
+	record.SetParams(
+		circuit.Key,
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		nil,
+		circuit.ChunkIndex,
+		0,
+		0, 0, 0, 0,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetPolicy(circuit.Policy, circuit.Fields, circuit.Thresholds, circuit.Texts)
+	record.SetComparison(GTE, circuit.Bits)
+	if len(circuit.Result) > 0 {
+		record.SetResult(circuit.Result[0])
+	}
+
+	// revealed fields are bytes of the verified plaintext
+	for i, idx := range circuit.Reveal {
+		field := circuit.Fields[idx]
+		SubstringMatch(api, circuit.Revealed[i], circuit.PlainChunks[field.Start:field.End], 0, len(circuit.Revealed[i]))
+	}
+
+	// verify
+	return record.Assert()
+}
+
+// Build compiles the spec into a circuit definition and its witness assignment
+func (spec *ClaimSpec) Build() (*ClaimCircuit, *ClaimCircuit, error) {
+
+	cipherSuite, err := ParseCipherSuite(spec.CipherSuite)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// record
+	key, err := hex.DecodeString(spec.Record.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv, err := hex.DecodeString(spec.Record.Iv)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := hex.DecodeString(spec.Record.Plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err := hex.DecodeString(spec.Record.Ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(key) != cipherSuite.KeyLen() || len(iv) != 12 || len(plaintext) != len(ciphertext) {
+		return nil, nil, errors.New("invalid record key, iv or length")
+	}
+	recordSize := spec.MaxRecordSize
+	if recordSize == 0 {
+		recordSize = len(plaintext)
+	}
+	if len(plaintext) > recordSize {
+		return nil, nil, errors.New("record exceeds max record size")
+	}
+
+	// pad with zero plaintext, the padded ciphertext must extend the record ciphertext
+	paddedPlaintext := make([]byte, recordSize)
+	copy(paddedPlaintext, plaintext)
+	paddedCiphertext, err := claimEncrypt(cipherSuite, key, iv, spec.Record.SequenceNumber, spec.Record.ChunkIndex, paddedPlaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(paddedCiphertext[:len(ciphertext)], ciphertext) {
+		return nil, nil, errors.New("ciphertext does not match key, iv and plaintext")
+	}
+
+	// fields
+	fieldIndex := map[string]int{}
+	fields := make([]PolicyField, len(spec.Fields))
+	var reveal []int
+	for i, field := range spec.Fields {
+		if field.Start < 0 || field.Start >= field.End || field.End > len(plaintext) {
+			return nil, nil, errors.New("field " + field.Name + " out of range")
+		}
+		if field.Key == "" || field.Start < len(field.Key) || string(plaintext[field.Start-len(field.Key):field.Start]) != field.Key {
+			return nil, nil, errors.New("field " + field.Name + " does not follow its key")
+		}
+		if _, ok := fieldIndex[field.Name]; ok {
+			return nil, nil, errors.New("duplicate field " + field.Name)
+		}
+		fieldIndex[field.Name] = i
+		fields[i] = PolicyField{Key: field.Key, Start: field.Start, End: field.End}
+		if field.Public {
+			reveal = append(reveal, i)
+		}
+	}
+
+	// predicates
+	if len(spec.Predicates) == 0 {
+		return nil, nil, errors.New("claim without predicates")
+	}
+	var thresholds []*big.Int
+	var texts []string
+	leaves := make([]PolicyNode, len(spec.Predicates))
+	for i, predicate := range spec.Predicates {
+		field, ok := fieldIndex[predicate.Field]
+		if !ok {
+			return nil, nil, errors.New("unknown field " + predicate.Field)
+		}
+		comparison, err := ParseComparison(predicate.Op)
+		if err != nil {
+			return nil, nil, err
+		}
+		if predicate.Text != "" {
+			if comparison != EQ && comparison != NEQ {
+				return nil, nil, errors.New("text predicates support == and != only")
+			}
+			leaves[i] = PolicyMatch(field, len(texts))
+			if comparison == NEQ {
+				leaves[i] = PolicyNot(leaves[i])
+			}
+			texts = append(texts, predicate.Text)
+		} else {
+			threshold, ok := new(big.Int).SetString(predicate.Value, 10)
+			if !ok || threshold.Sign() < 0 {
+				return nil, nil, errors.New("invalid predicate value " + predicate.Value)
+			}
+			leaves[i] = PolicyCompare(field, comparison, len(thresholds))
+			thresholds = append(thresholds, threshold)
+		}
+		if predicate.Not {
+			leaves[i] = PolicyNot(leaves[i])
+		}
+	}
+	var policy PolicyNode
+	switch spec.Combine {
+	case "", "and":
+		policy = PolicyAnd(leaves...)
+	case "or":
+		policy = PolicyOr(leaves...)
+	default:
+		return nil, nil, errors.New("unsupported combine " + spec.Combine)
+	}
+	bits := spec.Bits
+	if bits == 0 {
+		bits = 64
+	}
+
+	// circuit definition
+	newCircuit := func() *ClaimCircuit {
+		circuit := &ClaimCircuit{
+			Key:          make([]frontend.Variable, len(key)),
+			PlainChunks:  make([]frontend.Variable, recordSize),
+			CipherChunks: make([]frontend.Variable, recordSize),
+			Thresholds:   make([]frontend.Variable, len(thresholds)),
+			Texts:        make([][]frontend.Variable, len(texts)),
+			Revealed:     make([][]frontend.Variable, len(reveal)),
+			CipherSuite:  cipherSuite,
+			Fields:       fields,
+			Reveal:       reveal,
+			Policy:       policy,
+			Bits:         bits,
+		}
+		for i := range texts {
+			circuit.Texts[i] = make([]frontend.Variable, len(texts[i]))
+		}
+		for i, idx := range reveal {
+			circuit.Revealed[i] = make([]frontend.Variable, fields[idx].End-fields[idx].Start)
+		}
+		if spec.PublicResult {
+			circuit.Result = make([]frontend.Variable, 1)
+		}
+		return circuit
+	}
+	circuit := newCircuit()
+
+	// witness assignment
+	assignment := newCircuit()
+	for i := range key {
+		assignment.Key[i] = key[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = iv[i]
+	}
+	assignment.SequenceNumber = spec.Record.SequenceNumber
+	assignment.ChunkIndex = spec.Record.ChunkIndex
+	for i := 0; i < recordSize; i++ {
+		assignment.PlainChunks[i] = paddedPlaintext[i]
+		assignment.CipherChunks[i] = paddedCiphertext[i]
+	}
+	for i := range thresholds {
+		assignment.Thresholds[i] = thresholds[i]
+	}
+	for i := range texts {
+		for j := 0; j < len(texts[i]); j++ {
+			assignment.Texts[i][j] = texts[i][j]
+		}
+	}
+	for i, idx := range reveal {
+		for j := range assignment.Revealed[i] {
+			assignment.Revealed[i][j] = plaintext[fields[idx].Start+j]
+		}
+	}
+	if spec.PublicResult {
+		result, err := spec.evaluate(plaintext, fieldIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		assignment.Result[0] = result
+	}
+
+	return circuit, assignment, nil
+}
+
+// out of circuit evaluation of the claim for the public result
+func (spec *ClaimSpec) evaluate(plaintext []byte, fieldIndex map[string]int) (int, error) {
+	result := spec.Combine == "" || spec.Combine == "and"
+	for _, predicate := range spec.Predicates {
+		field := spec.Fields[fieldIndex[predicate.Field]]
+		value := string(plaintext[field.Start:field.End])
+
+		var holds bool
+		if predicate.Text != "" {
+			holds = (value == predicate.Text) == (predicate.Op == "==")
+		} else {
+			v, ok := new(big.Int).SetString(value, 10)
+			if !ok {
+				return 0, errors.New("field " + field.Name + " is not an integer")
+			}
+			threshold, _ := new(big.Int).SetString(predicate.Value, 10)
+			cmp := v.Cmp(threshold)
+			switch predicate.Op {
+			case ">":
+				holds = cmp > 0
+			case ">=":
+				holds = cmp >= 0
+			case "<":
+				holds = cmp < 0
+			case "<=":
+				holds = cmp <= 0
+			case "==":
+				holds = cmp == 0
+			case "!=":
+				holds = cmp != 0
+			}
+		}
+		holds = holds != predicate.Not
+
+		if spec.Combine == "or" {
+			result = result || holds
+		} else {
+			result = result && holds
+		}
+	}
+	if result {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// record keystream xor out of circuit, starting at block counter chunkIndex
+func claimEncrypt(cipherSuite CipherSuite, key, iv []byte, sequenceNumber uint64, chunkIndex uint32, plaintext []byte) ([]byte, error) {
+
+	// per-record nonce
+	nonce := make([]byte, 12)
+	copy(nonce, iv)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], sequenceNumber)
+	for i := 0; i < 8; i++ {
+		nonce[4+i] ^= seq[i]
+	}
+
+	ciphertext := make([]byte, len(plaintext))
+	if cipherSuite.IsAESGCM() {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		counter := make([]byte, 16)
+		copy(counter, nonce)
+		binary.BigEndian.PutUint32(counter[12:], chunkIndex)
+		cipher.NewCTR(block, counter).XORKeyStream(ciphertext, plaintext)
+	} else {
+		stream, err := chacha20.NewUnauthenticatedCipher(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		stream.SetCounter(chunkIndex)
+		stream.XORKeyStream(ciphertext, plaintext)
+	}
+	return ciphertext, nil
+}
+
+// execution of a claim spec, compile, setup, prove and verify
+func EvaluateClaim(backend string, compile bool, path string) (map[string]time.Duration, error) {
+
+	log.Debug().Str("spec", path).Msg("EvaluateClaim")
+
+	spec, err := LoadClaimSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	circuit, assignment, err := spec.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return ProofWithBackend(backend, compile, circuit, assignment, ecc.BN254)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestClaimSpec(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	spec, err := LoadClaimSpec("../claims/price.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// padded record, public name and public result
+	circuit, assignment, err := spec.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignment.Result[0] != 1 {
+		t.Fatalf("expected true claim, got %v", assignment.Result[0])
+	}
+	assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a false claim is provable with public result 0 but not with 1
+	spec.Predicates[1].Value = "40000"
+	circuit, assignment, err = spec.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignment.Result[0] != 0 {
+		t.Fatalf("expected false claim, got %v", assignment.Result[0])
+	}
+	assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assignment.Result[0] = 1
+	assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// asserted claims fail to prove when false
+	spec.PublicResult = false
+	circuit, assignment, err = spec.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// revealed bytes must match the plaintext
+	spec.Predicates[1].Value = "38000"
+	circuit, assignment, err = spec.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assignment.Revealed[0][0] = 'B'
+	assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// invalid specs are rejected by the builder
+	spec.Fields[1].Key = `"volume":"`
+	if _, _, err := spec.Build(); err == nil {
+		t.Error("expected error for mismatching field key")
+	}
+	spec.Fields[1].Key = `"price":"`
+	spec.Record.Ciphertext = "00" + spec.Record.Ciphertext[2:]
+	if _, _, err := spec.Build(); err == nil {
+		t.Error("expected error for mismatching ciphertext")
+	}
+	if _, err := ParseClaimSpec([]byte(`{"cipher_suite": "TLS_AES_128_GCM_SHA256", "threshold": 1}`)); err == nil {
+		t.Error("expected error for unknown spec key")
+	}
+}
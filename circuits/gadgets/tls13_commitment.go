@@ -0,0 +1,81 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gnarkHash "github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// value commitment evaluation
+type ValueCommitmentWrapper struct {
+	Value      frontend.Variable
+	Salt       frontend.Variable
+	Commitment frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *ValueCommitmentWrapper) Define(api frontend.API) error {
+
+	api.AssertIsEqual(ValueCommitment(api, circuit.Value, circuit.Salt), circuit.Commitment)
+
+	return nil
+}
+
+// ValueCommitment is the hiding commitment mimc(value || salt) of an extracted value
+func ValueCommitment(api frontend.API, value, salt frontend.Variable) frontend.Variable {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(value, salt)
+	return h.Sum()
+}
+
+// CommitValue computes the value commitment out of circuit, value and salt
+// must be bn254 scalar field elements
+func CommitValue(value, salt *big.Int) (*big.Int, error) {
+	modulus := ecc.BN254.ScalarField()
+	if value.Sign() < 0 || value.Cmp(modulus) >= 0 || salt.Sign() < 0 || salt.Cmp(modulus) >= 0 {
+		return nil, errors.New("value and salt must be field elements")
+	}
+	h := gnarkHash.MIMC_BN254.New()
+	var buf [32]byte
+	h.Write(value.FillBytes(buf[:]))
+	h.Write(salt.FillBytes(buf[:]))
+	return new(big.Int).SetBytes(h.Sum(nil)), nil
+}
+
+// OpenValueCommitment checks that value and salt open the commitment
+func OpenValueCommitment(commitment, value, salt *big.Int) (bool, error) {
+	c, err := CommitValue(value, salt)
+	if err != nil {
+		return false, err
+	}
+	return c.Cmp(commitment) == 0, nil
+}
+
+// NewCommitmentSalt samples a uniform salt in the bn254 scalar field
+func NewCommitmentSalt() (*big.Int, error) {
+	return rand.Int(rand.Reader, ecc.BN254.ScalarField())
+}
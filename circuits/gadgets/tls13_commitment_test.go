@@ -0,0 +1,112 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+func TestValueCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	value := big.NewInt(38002)
+	salt, err := NewCommitmentSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := CommitValue(value, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// in-circuit and out of circuit commitments agree
+	assignment := &ValueCommitmentWrapper{Value: value, Salt: salt, Commitment: commitment}
+	assert.SolvingSucceeded(&ValueCommitmentWrapper{}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// openings
+	ok, err := OpenValueCommitment(commitment, value, salt)
+	if err != nil || !ok {
+		t.Fatal("expected valid opening")
+	}
+	ok, err = OpenValueCommitment(commitment, big.NewInt(38003), salt)
+	if err != nil || ok {
+		t.Fatal("expected invalid opening")
+	}
+	if _, err := CommitValue(value, ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("expected error for salt outside the field")
+	}
+}
+
+func TestRecordCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	salt, err := NewCommitmentSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := CommitValue(big.NewInt(38002), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := newRecordCircuit(recordPlaintext, `"price"`, "38002")
+	newCircuit := func() *RecordCommitmentWrapper {
+		return &RecordCommitmentWrapper{
+			PlainChunks:    make([]frontend.Variable, len(recordPlaintext)),
+			CipherChunks:   make([]frontend.Variable, len(recordPlaintext)),
+			Substring:      make([]frontend.Variable, len(`"price"`)),
+			SubstringStart: record.SubstringStart,
+			SubstringEnd:   record.SubstringEnd,
+			ValueStart:     record.ValueStart,
+			ValueEnd:       record.ValueEnd,
+		}
+	}
+	assignment := newCircuit()
+	assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, recordPlaintext)
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 2
+	assignment.Substring = toVariables([]byte(`"price"`))
+	assignment.Salt = salt
+	assignment.ValueCommitment = commitment
+
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a different salt does not open the commitment
+	assignment.Salt = new(big.Int).Add(salt, big.NewInt(1))
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestValueCommitmentSalt(t *testing.T) {
+
+	// a commitment without its salt is rejected instead of panicking
+	circuits := []frontend.Circuit{
+		&Tls13OracleWrapper{ValueCommitment: make([]frontend.Variable, 1)},
+		&Tls13SessionDataWrapper{ValueCommitment: make([]frontend.Variable, 1)},
+	}
+	for _, circuit := range circuits {
+		if _, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit); err == nil {
+			t.Fatalf("%T: expected error for value commitment without salt", circuit)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package gadgets
 
-import "github.com/consensys/gnark/frontend"
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+)
 
 // substring evaluation
 type SubstringWrapper struct {
@@ -26,6 +32,19 @@ func SubstringMatch(api frontend.API, substring, totalString []frontend.Variable
 	}
 }
 
+// IsSubstringMatch returns 1 if substring equals totalString and 0 otherwise,
+// strings of different length never match
+func IsSubstringMatch(api frontend.API, substring, totalString []frontend.Variable) frontend.Variable {
+	if len(substring) != len(totalString) {
+		return 0
+	}
+	result := frontend.Variable(1)
+	for i := 0; i < len(substring); i++ {
+		result = api.And(result, api.IsZero(api.Sub(substring[i], totalString[i])))
+	}
+	return result
+}
+
 // gt/ lt evaluation
 type GTLTWrapper struct {
 	Threshold frontend.Variable
@@ -40,9 +59,178 @@ func (circuit *GTLTWrapper) Define(api frontend.API) error {
 	return nil
 }
 
-// it must hold v1 > v2 for GreaterThan to succeed
+// it must hold v1 >= v2 for GreaterThan to succeed
 // fails if v2 > v1
-// valueInteger > circuit.Threshold
+// valueInteger >= circuit.Threshold
 func GreaterThan(api frontend.API, v1, v2 frontend.Variable) {
 	api.AssertIsLessOrEqual(v2, v1)
 }
+
+// comparison operators of value against threshold
+type Comparison int
+
+const (
+	GTE Comparison = iota
+	GT
+	LT
+	LTE
+	EQ
+	NEQ
+)
+
+func ParseComparison(op string) (Comparison, error) {
+	switch op {
+	case ">=":
+		return GTE, nil
+	case ">":
+		return GT, nil
+	case "<":
+		return LT, nil
+	case "<=":
+		return LTE, nil
+	case "==":
+		return EQ, nil
+	case "!=":
+		return NEQ, nil
+	}
+	return GTE, errors.New("unsupported comparison " + op)
+}
+
+func (c Comparison) String() string {
+	switch c {
+	case GT:
+		return ">"
+	case LT:
+		return "<"
+	case LTE:
+		return "<="
+	case EQ:
+		return "=="
+	case NEQ:
+		return "!="
+	}
+	return ">="
+}
+
+// comparison evaluation
+type ComparisonWrapper struct {
+	Value      frontend.Variable
+	Threshold  frontend.Variable `gnark:",public"`
+	Comparison Comparison
+	Bits       int
+}
+
+// Define declares the circuit's constraints
+func (circuit *ComparisonWrapper) Define(api frontend.API) error {
+
+	AssertComparison(api, circuit.Comparison, circuit.Value, circuit.Threshold, circuit.Bits)
+
+	return nil
+}
+
+// AssertComparison asserts v1 op v2, both values must be below 2^bits
+func AssertComparison(api frontend.API, op Comparison, v1, v2 frontend.Variable, bits int) {
+	switch op {
+	case GT:
+		AssertGT(api, v1, v2, bits)
+	case LT:
+		AssertLT(api, v1, v2, bits)
+	case LTE:
+		AssertLTE(api, v1, v2, bits)
+	case EQ:
+		api.AssertIsEqual(v1, v2)
+	case NEQ:
+		AssertNotEqual(api, v1, v2)
+	default:
+		AssertGTE(api, v1, v2, bits)
+	}
+}
+
+// AssertGT asserts v1 > v2 for values below 2^bits
+func AssertGT(api frontend.API, v1, v2 frontend.Variable, bits int) {
+	AssertGTE(api, v1, api.Add(v2, 1), bits)
+}
+
+// AssertGTE asserts v1 >= v2 for values below 2^bits, the difference
+// v1 - v2 only stays below 2^bits if it does not wrap around the field
+func AssertGTE(api frontend.API, v1, v2 frontend.Variable, bits int) {
+	checkBits(api, bits)
+	rc := rangecheck.New(api)
+	rc.Check(v1, bits)
+	rc.Check(v2, bits)
+	rc.Check(api.Sub(v1, v2), bits)
+}
+
+// AssertLT asserts v1 < v2 for values below 2^bits
+func AssertLT(api frontend.API, v1, v2 frontend.Variable, bits int) {
+	AssertGT(api, v2, v1, bits)
+}
+
+// AssertLTE asserts v1 <= v2 for values below 2^bits
+func AssertLTE(api frontend.API, v1, v2 frontend.Variable, bits int) {
+	AssertGTE(api, v2, v1, bits)
+}
+
+// AssertInRange asserts lower <= v <= upper for values below 2^bits
+func AssertInRange(api frontend.API, v, lower, upper frontend.Variable, bits int) {
+	AssertGTE(api, v, lower, bits)
+	AssertLTE(api, v, upper, bits)
+}
+
+// AssertNotEqual asserts v1 != v2
+func AssertNotEqual(api frontend.API, v1, v2 frontend.Variable) {
+	api.AssertIsDifferent(v1, v2)
+}
+
+// IsGreater returns 1 if v1 > v2 and 0 otherwise for values below 2^bits.
+// v1 - v2 - 1 + 2^bits has its top bit set iff v1 > v2
+func IsGreater(api frontend.API, v1, v2 frontend.Variable, bits int) frontend.Variable {
+	checkBits(api, bits)
+	rc := rangecheck.New(api)
+	rc.Check(v1, bits)
+	rc.Check(v2, bits)
+	shifted := api.Add(api.Sub(v1, v2, 1), new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	return api.ToBinary(shifted, bits+1)[bits]
+}
+
+// IsComparison returns 1 if v1 op v2 holds and 0 otherwise for values below 2^bits
+func IsComparison(api frontend.API, op Comparison, v1, v2 frontend.Variable, bits int) frontend.Variable {
+	switch op {
+	case GT:
+		return IsGreater(api, v1, v2, bits)
+	case LT:
+		return IsGreater(api, v2, v1, bits)
+	case LTE:
+		return api.Sub(1, IsGreater(api, v1, v2, bits))
+	case EQ:
+		return api.IsZero(api.Sub(v1, v2))
+	case NEQ:
+		return api.Sub(1, api.IsZero(api.Sub(v1, v2)))
+	}
+	return api.Sub(1, IsGreater(api, v2, v1, bits))
+}
+
+// comparison result evaluation, the predicate result is public instead of asserted
+type ComparisonResultWrapper struct {
+	Value      frontend.Variable
+	Threshold  frontend.Variable `gnark:",public"`
+	Result     frontend.Variable `gnark:",public"`
+	Comparison Comparison
+	Bits       int
+}
+
+// Define declares the circuit's constraints
+func (circuit *ComparisonResultWrapper) Define(api frontend.API) error {
+
+	result := IsComparison(api, circuit.Comparison, circuit.Value, circuit.Threshold, circuit.Bits)
+	api.AssertIsEqual(result, circuit.Result)
+
+	return nil
+}
+
+// differences of values below 2^bits must not wrap around the field
+func checkBits(api frontend.API, bits int) {
+	if bits < 1 || bits+2 > api.Compiler().FieldBitLen() {
+		panic("invalid comparison bit width")
+	}
+}
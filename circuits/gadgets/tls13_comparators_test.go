@@ -0,0 +1,196 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// boolean comparison evaluation
+type isGreaterWrapper struct {
+	V1      frontend.Variable
+	V2      frontend.Variable
+	Greater frontend.Variable `gnark:",public"`
+	Bits    int
+}
+
+func (circuit *isGreaterWrapper) Define(api frontend.API) error {
+	api.AssertIsEqual(IsGreater(api, circuit.V1, circuit.V2, circuit.Bits), circuit.Greater)
+	return nil
+}
+
+// range evaluation
+type inRangeWrapper struct {
+	Value frontend.Variable
+	Lower frontend.Variable `gnark:",public"`
+	Upper frontend.Variable `gnark:",public"`
+	Bits  int
+}
+
+func (circuit *inRangeWrapper) Define(api frontend.API) error {
+	AssertInRange(api, circuit.Value, circuit.Lower, circuit.Upper, circuit.Bits)
+	return nil
+}
+
+func TestComparisons(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	bits := 32
+	comparisons := []struct {
+		value      int
+		comparison string
+		threshold  int
+		valid      bool
+	}{
+		{38002, ">", 38001, true},
+		{38002, ">", 38002, false},
+		{38002, ">=", 38002, true},
+		{38001, ">=", 38002, false},
+		{38001, "<", 38002, true},
+		{38002, "<", 38002, false},
+		{38002, "<=", 38002, true},
+		{38003, "<=", 38002, false},
+		{38002, "==", 38002, true},
+		{38003, "==", 38002, false},
+		{38003, "!=", 38002, true},
+		{38002, "!=", 38002, false},
+		// values must stay below 2^bits
+		{1 << 32, ">", 38002, false},
+		{38002, "<", 1 << 32, false},
+	}
+
+	for _, c := range comparisons {
+		comparison, err := ParseComparison(c.comparison)
+		if err != nil {
+			t.Fatal(err)
+		}
+		circuit := &ComparisonWrapper{Comparison: comparison, Bits: bits}
+		assignment := &ComparisonWrapper{Value: c.value, Threshold: c.threshold}
+		if c.valid {
+			assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+
+	// boolean variant
+	for _, c := range []struct{ v1, v2, greater int }{{5, 3, 1}, {3, 3, 0}, {3, 5, 0}, {1<<16 - 1, 0, 1}} {
+		assignment := &isGreaterWrapper{V1: c.v1, V2: c.v2, Greater: c.greater}
+		assert.SolvingSucceeded(&isGreaterWrapper{Bits: 16}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+	assignment := &isGreaterWrapper{V1: 5, V2: 3, Greater: 0}
+	assert.SolvingFailed(&isGreaterWrapper{Bits: 16}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// inclusive range
+	for _, c := range []struct {
+		value int
+		valid bool
+	}{{100, true}, {200, true}, {150, true}, {99, false}, {201, false}} {
+		assignment := &inRangeWrapper{Value: c.value, Lower: 100, Upper: 200}
+		if c.valid {
+			assert.SolvingSucceeded(&inRangeWrapper{Bits: 16}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(&inRangeWrapper{Bits: 16}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
+
+func TestRecordComparison(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func() *RecordWrapper {
+		circuit := newRecordCircuit(recordPlaintext, `"price"`, "38002")
+		circuit.Comparison = LT
+		circuit.Bits = 32
+		return circuit
+	}
+	newAssignment := func(threshold int) *RecordWrapper {
+		return newRecordAssignment(t, recordPlaintext, `"price"`, "38002", threshold)
+	}
+
+	// price < 40000 holds, price < 38002 does not
+	assert.SolvingSucceeded(newCircuit(), newAssignment(40000), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(), newAssignment(38002), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestComparisonResult(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// one circuit per operator proves both outcomes
+	for _, op := range []Comparison{GT, GTE, LT, LTE, EQ, NEQ} {
+		for _, c := range []struct{ value, threshold int }{{38001, 38002}, {38002, 38002}, {38003, 38002}} {
+			var holds bool
+			switch op {
+			case GT:
+				holds = c.value > c.threshold
+			case GTE:
+				holds = c.value >= c.threshold
+			case LT:
+				holds = c.value < c.threshold
+			case LTE:
+				holds = c.value <= c.threshold
+			case EQ:
+				holds = c.value == c.threshold
+			case NEQ:
+				holds = c.value != c.threshold
+			}
+			result := 0
+			if holds {
+				result = 1
+			}
+			circuit := &ComparisonResultWrapper{Comparison: op, Bits: 32}
+			assignment := &ComparisonResultWrapper{Value: c.value, Threshold: c.threshold, Result: result}
+			assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+			// the opposite result must fail
+			assignment.Result = 1 - result
+			assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
+
+func TestRecordResult(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func() *RecordResultWrapper {
+		circuit := &RecordResultWrapper{RecordWrapper: *newRecordCircuit(recordPlaintext, `"price"`, "38002")}
+		circuit.Comparison = GT
+		circuit.Bits = 32
+		return circuit
+	}
+	newAssignment := func(threshold, result int) *RecordResultWrapper {
+		return &RecordResultWrapper{
+			RecordWrapper: *newRecordAssignment(t, recordPlaintext, `"price"`, "38002", threshold),
+			Result:        result,
+		}
+	}
+
+	// price > 38000 is true, price > 40000 is false, both are provable
+	assert.SolvingSucceeded(newCircuit(), newAssignment(38000, 1), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingSucceeded(newCircuit(), newAssignment(40000, 0), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(), newAssignment(40000, 1), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// decryption still hard-fails
+	assignment := newAssignment(40000, 0)
+	assignment.PlainChunks[assignment.ValueStart] = '4'
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
@@ -1,9 +1,10 @@
 package gadgets
 
 import (
-	"math"
+	"math/big"
 
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
 )
 
 // str 2 int evaluation
@@ -25,18 +26,40 @@ func (circuit *Str2IntWrapper) Define(api frontend.API) error {
 	return nil
 }
 
-// gnark string to integer conversion
+// gnark string to integer conversion, every byte must be a digit and the
+// digit count must stay below the field size so that the sum cannot wrap
 func StringToInt(api frontend.API, valueString []frontend.Variable) frontend.Variable {
-	// aggregation number
+	if len(valueString) > intDigits(api) {
+		panic("integer string too long for the field")
+	}
+
+	// range checked digits
+	digits := newDigitTable(api)
+	indices := make([]frontend.Variable, len(valueString))
+	for i := range valueString {
+		indices[i] = api.Sub(valueString[i], 48)
+	}
+	toInt := digits.Lookup(indices...)
+
+	// aggregation number, front to back
 	sum := frontend.Variable(0)
-	// loop from back to front
-	for i := len(valueString); i > 0; i-- {
-		idx := len(valueString) - i
-
-		// expanded dezimal such that shift can be applied
-		// 4 bits cover numbers 0-9, little endian result, IMPORTANT: 8 required, otherwise unsatisfied constraint error
-		toInt := api.Sub(api.FromBinary(api.ToBinary(valueString[i-1], 8)...), 48)
-		sum = api.MulAcc(sum, toInt, int(math.Pow(float64(10), float64(idx))))
+	for i := range toInt {
+		sum = api.MulAcc(toInt[i], sum, 10)
 	}
 	return sum
 }
+
+// digit lookup, indices byte - 48 are only found for '0' to '9'
+// and all other bytes leave the constraint system unsatisfied
+func newDigitTable(api frontend.API) *logderivlookup.Table {
+	table := logderivlookup.New(api)
+	for i := 0; i < 10; i++ {
+		table.Insert(i)
+	}
+	return table
+}
+
+// number of decimal digits d with 10^d < p, integers with d digits do not wrap
+func intDigits(api frontend.API) int {
+	return len(api.Compiler().Field().String()) - 1
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// extractor value evaluation
+type extractValueWrapper struct {
+	PlainChunks []frontend.Variable
+	ValueStart  frontend.Variable
+	ValueEnd    frontend.Variable
+	Value       frontend.Variable `gnark:",public"`
+	MaxValueLen int
+}
+
+func (circuit *extractValueWrapper) Define(api frontend.API) error {
+	extractor := NewExtractor(api, circuit.PlainChunks, circuit.MaxValueLen)
+	value := extractor.Value(circuit.ValueStart, circuit.ValueEnd, circuit.MaxValueLen)
+	api.AssertIsEqual(value, circuit.Value)
+	return nil
+}
+
+func TestStringToInt(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// malformed values are assigned the integer the unchecked conversion
+	// would have produced, e.g. ':' decodes to 10
+	values := []struct {
+		value   string
+		integer int
+		valid   bool
+	}{
+		{"38002", 38002, true},
+		{"0", 0, true},
+		{"3800:", 38010, false},
+		{"38/02", 37992, false},
+		{" 3802", -16*10000 + 3802, false},
+		{"38a02", 49*100 + 38002, false},
+	}
+
+	for _, v := range values {
+		newCircuit := func() *Str2IntWrapper {
+			return &Str2IntWrapper{
+				PlainChunks: make([]frontend.Variable, len(v.value)+2),
+				ValueStart:  1,
+				ValueEnd:    len(v.value) + 1,
+			}
+		}
+		plaintext := `"` + v.value + `"`
+
+		assignment := newCircuit()
+		for i := 0; i < len(plaintext); i++ {
+			assignment.PlainChunks[i] = plaintext[i]
+		}
+		assignment.Value = v.integer
+
+		if v.valid {
+			assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
+
+func TestExtractorValue(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	plaintext := `{"price":"38002","id":"3:02"}`
+	maxValueLen := 8
+
+	newCircuit := func() *extractValueWrapper {
+		return &extractValueWrapper{
+			PlainChunks: make([]frontend.Variable, len(plaintext)),
+			MaxValueLen: maxValueLen,
+		}
+	}
+	newAssignment := func(start, end, value int) *extractValueWrapper {
+		assignment := newCircuit()
+		for i := 0; i < len(plaintext); i++ {
+			assignment.PlainChunks[i] = plaintext[i]
+		}
+		assignment.ValueStart = start
+		assignment.ValueEnd = end
+		assignment.Value = value
+		return assignment
+	}
+
+	// the closing quote past the value end is not range checked
+	assert.SolvingSucceeded(newCircuit(), newAssignment(10, 15, 38002), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// the quote inside the value is rejected
+	assert.SolvingFailed(newCircuit(), newAssignment(10, 16, 380020-14), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// ':' inside the value is rejected
+	assert.SolvingFailed(newCircuit(), newAssignment(23, 27, 3*1000+10*100+2), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// empty values and values longer than maxValueLen are rejected
+	assert.SolvingFailed(newCircuit(), newAssignment(10, 10, 0), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(), newAssignment(1, 1+maxValueLen+1, 0), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
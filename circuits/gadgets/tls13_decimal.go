@@ -0,0 +1,223 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// character classes of the decimal lookup table, digits map to their value
+const (
+	decimalPlus = iota + 10
+	decimalMinus
+	decimalDot
+	decimalExp
+	decimalInvalid
+)
+
+// str 2 decimal evaluation
+type Str2DecimalWrapper struct {
+	PlainChunks []frontend.Variable
+	Value       frontend.Variable `gnark:",public"`
+	ValueStart  int               `gnark:",public"`
+	ValueEnd    int               `gnark:",public"`
+	Scale       int
+}
+
+// Define declares the circuit's constraints
+func (circuit *Str2DecimalWrapper) Define(api frontend.API) error {
+
+	valueString := circuit.PlainChunks[circuit.ValueStart:circuit.ValueEnd]
+	decimal := StringToDecimal(api, valueString, circuit.Scale)
+
+	api.AssertIsEqual(decimal.Value(api), circuit.Value)
+
+	return nil
+}
+
+// signed fixed-point decimal, Magnitude is the absolute value times 10^scale
+type Decimal struct {
+	Negative  frontend.Variable
+	Magnitude frontend.Variable
+}
+
+// Value returns the scaled value, negative values are field negations
+func (d Decimal) Value(api frontend.API) frontend.Variable {
+	return api.Select(d.Negative, api.Neg(d.Magnitude), d.Magnitude)
+}
+
+// gnark string to decimal conversion of [+-]digits[.digits][(e|E)[+-]digits],
+// the result is scaled by 10^scale and values with more fractional digits fail
+func StringToDecimal(api frontend.API, valueString []frontend.Variable, scale int) Decimal {
+	active := make([]frontend.Variable, len(valueString))
+	for i := range active {
+		active[i] = 1
+	}
+	return parseDecimal(api, valueString, active, scale)
+}
+
+// Decimal converts the chars in [start, end) to a scaled decimal, at most maxLen chars
+func (e *Extractor) Decimal(start, end frontend.Variable, maxLen, scale int) Decimal {
+
+	chars := e.Substring(start, maxLen)
+	length := e.api.Sub(end, start)
+
+	// char i is part of the value while i < length
+	active := make([]frontend.Variable, maxLen)
+	found := frontend.Variable(0)
+	for i := 0; i < maxLen; i++ {
+		found = e.api.Add(found, e.api.IsZero(e.api.Sub(length, i)))
+		active[i] = e.api.Sub(1, found)
+	}
+	found = e.api.Add(found, e.api.IsZero(e.api.Sub(length, maxLen)))
+	e.api.AssertIsEqual(found, 1)
+	e.api.AssertIsLessOrEqual(end, e.length)
+
+	return parseDecimal(e.api, chars, active, scale)
+}
+
+// DecimalGreaterThan compares signed scaled values, it must hold v1 >= v2
+func DecimalGreaterThan(api frontend.API, v1, v2 frontend.Variable) {
+	bias := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimalDigits(api))), nil)
+	GreaterThan(api, api.Add(v1, bias), api.Add(v2, bias))
+}
+
+// DecimalToScaled converts a decimal string out of circuit, e.g. for thresholds
+func DecimalToScaled(value string, scale int) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, errors.New("invalid decimal " + value)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !r.IsInt() {
+		return nil, errors.New("decimal " + value + " exceeds scale")
+	}
+	return r.Num(), nil
+}
+
+// number of decimal digits d such that signed values below 10^d
+// can be biased into the field without wrapping, 2*10^d < p
+func decimalDigits(api frontend.API) int {
+	bound := new(big.Int).Rsh(api.Compiler().Field(), 1)
+	return len(bound.String()) - 1
+}
+
+// parses the active prefix of chars, the grammar is checked position by position
+func parseDecimal(api frontend.API, chars, active []frontend.Variable, scale int) Decimal {
+
+	// magnitude must stay below 10^maxDigits, mantissa digits are bounded by len(chars)
+	maxDigits := decimalDigits(api)
+	maxShift := maxDigits - len(chars)
+	if maxShift < scale {
+		panic("decimal string too long for the field")
+	}
+
+	// byte classes, out of range bytes fail the lookup
+	classes := logderivlookup.New(api)
+	for c := 0; c < 256; c++ {
+		switch {
+		case c >= '0' && c <= '9':
+			classes.Insert(c - '0')
+		case c == '+':
+			classes.Insert(decimalPlus)
+		case c == '-':
+			classes.Insert(decimalMinus)
+		case c == '.':
+			classes.Insert(decimalDot)
+		case c == 'e' || c == 'E':
+			classes.Insert(decimalExp)
+		default:
+			classes.Insert(decimalInvalid)
+		}
+	}
+	powers := logderivlookup.New(api)
+	for i := 0; i <= maxShift; i++ {
+		powers.Insert(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(i)), nil))
+	}
+
+	codes := classes.Lookup(chars...)
+
+	negative := frontend.Variable(0)
+	expNegative := frontend.Variable(0)
+	mantissa := frontend.Variable(0)
+	exponent := frontend.Variable(0)
+	fracDigits := frontend.Variable(0)
+	seenDigit := frontend.Variable(0)
+	seenDot := frontend.Variable(0)
+	seenExp := frontend.Variable(0)
+	seenExpDigit := frontend.Variable(0)
+	prevExp := frontend.Variable(0)
+	for i, code := range codes {
+		isPlus := api.IsZero(api.Sub(code, decimalPlus))
+		isMinus := api.IsZero(api.Sub(code, decimalMinus))
+		isDot := api.IsZero(api.Sub(code, decimalDot))
+		isExp := api.IsZero(api.Sub(code, decimalExp))
+		isInvalid := api.IsZero(api.Sub(code, decimalInvalid))
+		isSign := api.Add(isPlus, isMinus)
+		isDigit := api.Sub(1, isSign, isDot, isExp, isInvalid)
+		a := active[i]
+
+		// no invalid bytes
+		api.AssertIsEqual(api.Mul(a, isInvalid), 0)
+
+		// signs lead the mantissa or follow the exponent marker
+		signAllowed := prevExp
+		if i == 0 {
+			signAllowed = 1
+		}
+		api.AssertIsEqual(api.Mul(a, isSign, api.Sub(1, signAllowed)), 0)
+
+		// at most one dot, inside the mantissa
+		api.AssertIsEqual(api.Mul(a, isDot, api.Add(seenDot, seenExp)), 0)
+
+		// one exponent marker after mantissa digits
+		api.AssertIsEqual(api.Mul(a, isExp, api.Add(api.Sub(1, seenDigit), seenExp)), 0)
+
+		// accumulate digits
+		digit := api.Mul(code, isDigit)
+		inMantissa := api.Mul(a, isDigit, api.Sub(1, seenExp))
+		inExp := api.Mul(a, isDigit, seenExp)
+		mantissa = api.Select(inMantissa, api.MulAcc(digit, mantissa, 10), mantissa)
+		exponent = api.Select(inExp, api.MulAcc(digit, exponent, 10), exponent)
+		fracDigits = api.Add(fracDigits, api.Mul(inMantissa, seenDot))
+
+		negative = api.Add(negative, api.Mul(a, isMinus, api.Sub(1, seenExp)))
+		expNegative = api.Add(expNegative, api.Mul(a, isMinus, seenExp))
+
+		seenDigit = api.Or(seenDigit, inMantissa)
+		seenExpDigit = api.Or(seenExpDigit, inExp)
+		seenDot = api.Or(seenDot, api.Mul(a, isDot))
+		prevExp = api.Mul(a, isExp)
+		seenExp = api.Or(seenExp, prevExp)
+	}
+
+	// mantissa digits are required, exponent digits if there is a marker
+	api.AssertIsEqual(seenDigit, 1)
+	api.AssertIsEqual(api.Mul(seenExp, api.Sub(1, seenExpDigit)), 0)
+
+	// shift = scale - fracDigits +/- exponent must lie in [0, maxShift],
+	// negative shifts wrap around the field and fail the lookup
+	signedExp := api.Select(expNegative, api.Neg(exponent), exponent)
+	shift := api.Add(api.Sub(scale, fracDigits), signedExp)
+	power := powers.Lookup(shift)[0]
+
+	return Decimal{Negative: negative, Magnitude: api.Mul(mantissa, power)}
+}
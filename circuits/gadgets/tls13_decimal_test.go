@@ -0,0 +1,133 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// signed decimal comparison evaluation
+type decimalGTWrapper struct {
+	Value     frontend.Variable
+	Threshold frontend.Variable `gnark:",public"`
+}
+
+func (circuit *decimalGTWrapper) Define(api frontend.API) error {
+	DecimalGreaterThan(api, circuit.Value, circuit.Threshold)
+	return nil
+}
+
+func TestStringToDecimal(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	scale := 2
+	values := []struct {
+		value string
+		valid bool
+	}{
+		{"38002", true},
+		{"38002.15", true},
+		{"38002.2", true},
+		{"-17.5", true},
+		{"+0.01", true},
+		{".5", true},
+		{"1.5e3", true},
+		{"-25E-2", true},
+		{"1e+1", true},
+		{"38002.155", false},
+		{"1e-3", false},
+		{"--1", false},
+		{"1-2", false},
+		{"1.2.3", false},
+		{"1e", false},
+		{"e5", false},
+		{"-", false},
+		{"3:02", false},
+		{"12 ", false},
+	}
+
+	for _, v := range values {
+		newCircuit := func() *Str2DecimalWrapper {
+			return &Str2DecimalWrapper{
+				PlainChunks: make([]frontend.Variable, len(v.value)+2),
+				ValueStart:  1,
+				ValueEnd:    len(v.value) + 1,
+				Scale:       scale,
+			}
+		}
+		plaintext := `"` + v.value + `"`
+
+		assignment := newCircuit()
+		for i := 0; i < len(plaintext); i++ {
+			assignment.PlainChunks[i] = plaintext[i]
+		}
+		assignment.Value = 0
+		if expected, err := DecimalToScaled(v.value, scale); err == nil {
+			assignment.Value = expected
+		}
+
+		if v.valid {
+			assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
+
+func TestDecimalGreaterThan(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	comparisons := []struct {
+		value     string
+		threshold string
+		valid     bool
+	}{
+		{"38002.2", "38002.15", true},
+		{"38002.1", "38002.15", false},
+		{"-1", "-2.5", true},
+		{"-3", "-2.5", false},
+		{"0", "-0.01", true},
+		{"-0.01", "0", false},
+	}
+
+	for _, c := range comparisons {
+		value, err := DecimalToScaled(c.value, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		threshold, err := DecimalToScaled(c.threshold, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assignment := &decimalGTWrapper{Value: value, Threshold: threshold}
+		if c.valid {
+			assert.SolvingSucceeded(&decimalGTWrapper{}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(&decimalGTWrapper{}, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+
+	// values beyond the scale are rejected out of circuit
+	if _, err := DecimalToScaled("38002.155", 2); err == nil {
+		t.Error("expected error for value beyond scale")
+	}
+}
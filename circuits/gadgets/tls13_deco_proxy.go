@@ -40,6 +40,9 @@ type Tls13DecoProxyWrapper struct {
 	Zeros     [16]frontend.Variable `gnark:",public"`
 	ECB0      [16]frontend.Variable `gnark:",public"`
 	ECBK      [16]frontend.Variable `gnark:",public"`
+	// key commitment scheme and secret salt
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
 }
 
 // Define declares the circuit's constraints
@@ -50,6 +53,7 @@ func (circuit *Tls13DecoProxyWrapper) Define(api frontend.API) error {
 
 	// set data
 	session_data.SetCommitParams(circuit.TkCommit)
+	session_data.SetCommitScheme(circuit.CommitScheme, circuit.KeySalt)
 	session_data.SetAuthtagParams(
 		circuit.IvCounter,
 		circuit.Zeros,
@@ -71,9 +75,7 @@ func (circuit *Tls13DecoProxyWrapper) Define(api frontend.API) error {
 	)
 
 	// verify everything
-	session_data.Assert()
-
-	return nil
+	return session_data.Assert()
 }
 
 type Tls13DecoProxy struct {
@@ -99,7 +101,9 @@ type Tls13DecoProxy struct {
 	ECBK      [16]frontend.Variable // `gnark:",public"`
 
 	// commitment params
-	TkCommit [32]frontend.Variable // `gnark:",public"`
+	TkCommit     [32]frontend.Variable // `gnark:",public"`
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
 }
 
 func NewTls13DecoProxy(api frontend.API) Tls13DecoProxy {
@@ -131,15 +135,23 @@ func (circuit *Tls13DecoProxy) SetCommitParams(tkCommit [32]frontend.Variable) {
 	circuit.TkCommit = tkCommit
 }
 
+// optional key commitment scheme, defaults to the unsalted sha256(tk). salted
+// schemes take a secret 32 byte salt
+func (circuit *Tls13DecoProxy) SetCommitScheme(scheme KeyCommitScheme, salt []frontend.Variable) {
+	circuit.CommitScheme = scheme
+	circuit.KeySalt = salt
+}
+
 // Define declares the circuit's constraints
-func (circuit *Tls13DecoProxy) Assert() {
+func (circuit *Tls13DecoProxy) Assert() error {
 
 	// commit verification
 
 	// init
-	sha := NewSHA256(circuit.api)
-	sha.Write(circuit.Key[:])
-	keyCommit := sha.Sum()
+	keyCommit, err := KeyCommitment(circuit.api, circuit.CommitScheme, circuit.Key[:], circuit.KeySalt)
+	if err != nil {
+		return err
+	}
 
 	// constraints check
 	for i := 0; i < len(circuit.TkCommit); i++ {
@@ -152,10 +164,12 @@ func (circuit *Tls13DecoProxy) Assert() {
 	tag := NewTls13AuthTag(circuit.api)
 
 	// type conversion
-	tag.SetParams(circuit.Key, circuit.IvCounter, circuit.Zeros, circuit.ECB0, circuit.ECBK)
+	tag.SetParams(circuit.Key[:], circuit.IvCounter, circuit.Zeros, circuit.ECB0, circuit.ECBK)
 
 	// verify tag
-	tag.Assert()
+	if err := tag.Assert(); err != nil {
+		return err
+	}
 
 	// policy-based record verification
 
@@ -164,7 +178,7 @@ func (circuit *Tls13DecoProxy) Assert() {
 
 	// insert data
 	record.SetParams(
-		circuit.Key,
+		circuit.Key[:],
 		circuit.Iv,
 		circuit.PlainChunks,
 		circuit.CipherChunks,
@@ -178,5 +192,5 @@ func (circuit *Tls13DecoProxy) Assert() {
 	)
 
 	// verify
-	record.Assert()
+	return record.Assert()
 }
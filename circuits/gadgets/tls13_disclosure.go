@@ -0,0 +1,74 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import "github.com/consensys/gnark/frontend"
+
+// redaction evaluation
+type RedactWrapper struct {
+	PlainChunks []frontend.Variable
+	Mask        []frontend.Variable `gnark:",public"`
+	Revealed    []frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *RedactWrapper) Define(api frontend.API) error {
+
+	AssertRedacted(api, circuit.PlainChunks, circuit.Mask, circuit.Revealed)
+
+	return nil
+}
+
+// Redact keeps plaintext bytes where the mask bit is set and zeroes the others
+func Redact(api frontend.API, plaintext, mask []frontend.Variable) []frontend.Variable {
+	out := make([]frontend.Variable, len(plaintext))
+	for i := 0; i < len(plaintext); i++ {
+		api.AssertIsBoolean(mask[i])
+		out[i] = api.Mul(mask[i], plaintext[i])
+	}
+	return out
+}
+
+// AssertRedacted checks that revealed is the redacted plaintext
+func AssertRedacted(api frontend.API, plaintext, mask, revealed []frontend.Variable) {
+	redacted := Redact(api, plaintext, mask)
+	for i := 0; i < len(plaintext); i++ {
+		api.AssertIsEqual(redacted[i], revealed[i])
+	}
+}
+
+// RedactBytes is the out of circuit redaction, e.g. for witness assignment
+func RedactBytes(plaintext, mask []byte) []byte {
+	out := make([]byte, len(plaintext))
+	for i := range plaintext {
+		if mask[i] == 1 {
+			out[i] = plaintext[i]
+		}
+	}
+	return out
+}
+
+// RangeMask sets the mask bits of plaintext[start:end] for each range
+func RangeMask(length int, ranges ...PolicyField) []byte {
+	mask := make([]byte, length)
+	for _, r := range ranges {
+		for i := r.Start; i < r.End; i++ {
+			mask[i] = 1
+		}
+	}
+	return mask
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordDisclosure(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	plaintext := `{"price":"38002","currency":"EUR"}`
+	valueStart := strings.Index(plaintext, "38002")
+
+	// reveal the currency field only
+	currencyStart := strings.Index(plaintext, `"currency"`)
+	mask := RangeMask(len(plaintext), PolicyField{Start: currencyStart, End: currencyStart + len(`"currency":"EUR"`)})
+	revealed := RedactBytes([]byte(plaintext), mask)
+
+	newCircuit := func() *RecordDisclosureWrapper {
+		return &RecordDisclosureWrapper{
+			RecordWrapper: *newRecordCircuit(plaintext, `"price"`, "38002"),
+			Mask:          make([]frontend.Variable, len(plaintext)),
+			Revealed:      make([]frontend.Variable, len(plaintext)),
+		}
+	}
+	newAssignment := func() *RecordDisclosureWrapper {
+		return &RecordDisclosureWrapper{
+			RecordWrapper: *newRecordAssignment(t, plaintext, `"price"`, "38002", 38000),
+			Mask:          toVariables(mask),
+			Revealed:      toVariables(revealed),
+		}
+	}
+
+	assert.SolvingSucceeded(newCircuit(), newAssignment(), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// revealed bytes must come from the decrypted record
+	assignment := newAssignment()
+	assignment.Revealed[currencyStart+len(`"currency":"`)] = 'U'
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// hidden bytes must be zero
+	assignment = newAssignment()
+	assignment.Revealed[valueStart] = '3'
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// mask bits must be boolean
+	assignment = newAssignment()
+	assignment.Mask[valueStart] = 2
+	assignment.Revealed[valueStart] = 2 * int(plaintext[valueStart])
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
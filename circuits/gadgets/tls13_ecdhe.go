@@ -0,0 +1,277 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+)
+
+// (ec)dhe groups of the tls13 key share extension
+type KeyExchange int
+
+const (
+	// shared secret is a witness, no key exchange in-circuit
+	KeyExchangeNone KeyExchange = iota
+	KeyExchangeX25519
+	KeyExchangeP256
+)
+
+func ParseKeyExchange(name string) (KeyExchange, error) {
+	switch name {
+	case "", "none":
+		return KeyExchangeNone, nil
+	case "x25519":
+		return KeyExchangeX25519, nil
+	case "secp256r1":
+		return KeyExchangeP256, nil
+	}
+	return KeyExchangeNone, errors.New("unsupported key exchange " + name)
+}
+
+func (kex KeyExchange) String() string {
+	switch kex {
+	case KeyExchangeX25519:
+		return "x25519"
+	case KeyExchangeP256:
+		return "secp256r1"
+	}
+	return "none"
+}
+
+// key share length in bytes, p256 shares are uncompressed points
+func (kex KeyExchange) ShareLen() int {
+	if kex == KeyExchangeP256 {
+		return 65
+	}
+	return 32
+}
+
+// ecdhe evaluation
+type EcdheWrapper struct {
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable `gnark:",public"`
+	SharedSecret [32]frontend.Variable
+}
+
+// Define declares the circuit's constraints
+func (circuit *EcdheWrapper) Define(api frontend.API) error {
+
+	secret, err := ECDHE(api, circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(secret[i], circuit.SharedSecret[i])
+	}
+
+	return nil
+}
+
+// ECDHE computes the tls13 shared secret from the 32 byte client private key
+// and the server key share, the secret is the x25519 output or the big
+// endian x coordinate of the p256 shared point
+func ECDHE(api frontend.API, kex KeyExchange, clientSecret, serverShare []frontend.Variable) ([]frontend.Variable, error) {
+
+	if len(clientSecret) != 32 || len(serverShare) != kex.ShareLen() {
+		return nil, errors.New("invalid key share length for " + kex.String())
+	}
+
+	switch kex {
+	case KeyExchangeX25519:
+		return x25519(api, clientSecret, serverShare)
+	case KeyExchangeP256:
+		return p256(api, clientSecret, serverShare)
+	}
+
+	return nil, errors.New("unsupported key exchange " + kex.String())
+}
+
+// ECDHESharedSecret computes the shared secret out of circuit
+func ECDHESharedSecret(kex KeyExchange, clientSecret, serverShare []byte) ([]byte, error) {
+
+	var curve ecdh.Curve
+	switch kex {
+	case KeyExchangeX25519:
+		curve = ecdh.X25519()
+	case KeyExchangeP256:
+		curve = ecdh.P256()
+	default:
+		return nil, errors.New("unsupported key exchange " + kex.String())
+	}
+
+	priv, err := curve.NewPrivateKey(clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := curve.NewPublicKey(serverShare)
+	if err != nil {
+		return nil, err
+	}
+	return priv.ECDH(pub)
+}
+
+// p256 scalar multiplication of the server point, the point is checked to
+// be on the curve and the scalar must not reduce to zero
+func p256(api frontend.API, clientSecret, serverShare []frontend.Variable) ([]frontend.Variable, error) {
+
+	curve, err := sw_emulated.New[emparams.P256Fp, emparams.P256Fr](api, sw_emulated.GetP256Params())
+	if err != nil {
+		return nil, err
+	}
+	baseField, err := emulated.NewField[emparams.P256Fp](api)
+	if err != nil {
+		return nil, err
+	}
+	scalarField, err := emulated.NewField[emparams.P256Fr](api)
+	if err != nil {
+		return nil, err
+	}
+
+	// uncompressed point 0x04 || x || y
+	api.AssertIsEqual(serverShare[0], 4)
+	point := sw_emulated.AffinePoint[emparams.P256Fp]{
+		X: *baseField.FromBits(bytesToBitsBE(api, serverShare[1:33])...),
+		Y: *baseField.FromBits(bytesToBitsBE(api, serverShare[33:65])...),
+	}
+	curve.AssertIsOnCurve(&point)
+
+	scalar := scalarField.FromBits(bytesToBitsBE(api, clientSecret)...)
+	api.AssertIsEqual(scalarField.IsZero(scalar), 0)
+	shared := curve.ScalarMul(&point, scalar)
+
+	return bitsToBytesBE(api, baseField.ToBitsCanonical(&shared.X), 32), nil
+}
+
+// field of curve25519, 2^255 - 19
+type x25519Fp struct{}
+
+var x25519Modulus = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+func (x25519Fp) NbLimbs() uint     { return 4 }
+func (x25519Fp) BitsPerLimb() uint { return 64 }
+func (x25519Fp) IsPrime() bool     { return true }
+func (x25519Fp) Modulus() *big.Int { return x25519Modulus }
+
+// montgomery ladder of rfc 7748 over little endian scalar and u coordinate.
+// low order shares end in z2 = 0, z2 must be invertible and the all-zero
+// output is rejected as in rfc 7748 section 6.1
+func x25519(api frontend.API, clientSecret, serverShare []frontend.Variable) ([]frontend.Variable, error) {
+
+	field, err := emulated.NewField[x25519Fp](api)
+	if err != nil {
+		return nil, err
+	}
+
+	// clamped scalar, bit 254 is set and bits 0 to 2 cleared
+	k := bytesToBitsLE(api, clientSecret)
+	k[0], k[1], k[2] = 0, 0, 0
+	k[254] = 1
+
+	// the most significant bit of u is masked
+	u := field.FromBits(bytesToBitsLE(api, serverShare)[:255]...)
+
+	a24 := big.NewInt(121665)
+	x1 := u
+	x2, z2 := field.One(), field.Zero()
+	x3, z3 := u, field.One()
+
+	var prev frontend.Variable = 0
+	for t := 254; t >= 0; t-- {
+
+		// conditional swap on bit changes
+		swap := api.Xor(prev, k[t])
+		prev = k[t]
+		x2, x3 = field.Select(swap, x3, x2), field.Select(swap, x2, x3)
+		z2, z3 = field.Select(swap, z3, z2), field.Select(swap, z2, z3)
+
+		a := field.Add(x2, z2)
+		aa := field.Mul(a, a)
+		b := field.Sub(x2, z2)
+		bb := field.Mul(b, b)
+		e := field.Sub(aa, bb)
+		c := field.Add(x3, z3)
+		d := field.Sub(x3, z3)
+		da := field.Mul(d, a)
+		cb := field.Mul(c, b)
+
+		sum := field.Add(da, cb)
+		x3 = field.Mul(sum, sum)
+		diff := field.Sub(da, cb)
+		z3 = field.Mul(x1, field.Mul(diff, diff))
+		x2 = field.Mul(aa, bb)
+		z2 = field.Mul(e, field.Add(aa, field.MulConst(e, a24)))
+	}
+	x2 = field.Select(prev, x3, x2)
+	z2 = field.Select(prev, z3, z2)
+
+	out := field.Mul(x2, field.Inverse(z2))
+	api.AssertIsEqual(field.IsZero(out), 0)
+
+	return bitsToBytesLE(api, field.ToBitsCanonical(out), 32), nil
+}
+
+// bits of big endian bytes, least significant bit first
+func bytesToBitsBE(api frontend.API, in []frontend.Variable) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 8*len(in))
+	for i := len(in) - 1; i >= 0; i-- {
+		bits = append(bits, api.ToBinary(in[i], 8)...)
+	}
+	return bits
+}
+
+// bits of little endian bytes, least significant bit first
+func bytesToBitsLE(api frontend.API, in []frontend.Variable) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 8*len(in))
+	for i := 0; i < len(in); i++ {
+		bits = append(bits, api.ToBinary(in[i], 8)...)
+	}
+	return bits
+}
+
+// packs least significant first bits into n little endian bytes
+func bitsToBytesLE(api frontend.API, bits []frontend.Variable, n int) []frontend.Variable {
+	out := make([]frontend.Variable, n)
+	for i := 0; i < n; i++ {
+		byteBits := make([]frontend.Variable, 8)
+		for j := 0; j < 8; j++ {
+			byteBits[j] = 0
+			if 8*i+j < len(bits) {
+				byteBits[j] = bits[8*i+j]
+			}
+		}
+		out[i] = api.FromBinary(byteBits...)
+	}
+	return out
+}
+
+// packs least significant first bits into n big endian bytes
+func bitsToBytesBE(api frontend.API, bits []frontend.Variable, n int) []frontend.Variable {
+	le := bitsToBytesLE(api, bits, n)
+	out := make([]frontend.Variable, n)
+	for i := 0; i < n; i++ {
+		out[i] = le[n-1-i]
+	}
+	return out
+}
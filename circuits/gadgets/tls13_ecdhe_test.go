@@ -0,0 +1,134 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/ecdh"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestECDHE(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// rfc 7748 section 6.1
+	x25519Secret := mustHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	x25519Share := mustHex("de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	x25519Shared := mustHex("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	// p256 shares of fixed private keys
+	p256Secret := mustHex("c88f01f510d9ac3f70a292daa2316de544e9aab8afe84049c62a9c57862d1433")
+	serverKey, err := ecdh.P256().NewPrivateKey(mustHex("c6ef9c5d78ae012a011164acb397ce2088685d8f06bf9be0b283ab46476bee53"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Share := serverKey.PublicKey().Bytes()
+	p256Shared, err := ECDHESharedSecret(KeyExchangeP256, p256Secret, p256Share)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := ECDHESharedSecret(KeyExchangeX25519, x25519Secret, x25519Share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(x25519Shared, shared)
+
+	for _, c := range []struct {
+		kex    KeyExchange
+		secret []byte
+		share  []byte
+		shared []byte
+	}{
+		{KeyExchangeX25519, x25519Secret, x25519Share, x25519Shared},
+		{KeyExchangeP256, p256Secret, p256Share, p256Shared},
+	} {
+		newCircuit := func() *EcdheWrapper {
+			return &EcdheWrapper{
+				KeyExchange:  c.kex,
+				ClientSecret: make([]frontend.Variable, 32),
+				ServerShare:  make([]frontend.Variable, c.kex.ShareLen()),
+			}
+		}
+		newAssignment := func(secret []byte) *EcdheWrapper {
+			assignment := newCircuit()
+			assignment.ClientSecret = toVariables(secret)
+			assignment.ServerShare = toVariables(c.share)
+			for i := 0; i < 32; i++ {
+				assignment.SharedSecret[i] = c.shared[i]
+			}
+			return assignment
+		}
+
+		assert.SolvingSucceeded(newCircuit(), newAssignment(c.secret), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// a different client key does not open the shared secret
+		wrong := append([]byte{}, c.secret...)
+		wrong[5] ^= 1
+		assert.SolvingFailed(newCircuit(), newAssignment(wrong), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestECDHELowOrder(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	x25519Secret := mustHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+
+	// p256 group order
+	p256Order := mustHex("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551")
+	serverKey, err := ecdh.P256().NewPrivateKey(mustHex("c6ef9c5d78ae012a011164acb397ce2088685d8f06bf9be0b283ab46476bee53"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Share := serverKey.PublicKey().Bytes()
+
+	for _, c := range []struct {
+		kex    KeyExchange
+		secret []byte
+		share  []byte
+	}{
+		// u = 0 and u = 1 have low order
+		{KeyExchangeX25519, x25519Secret, make([]byte, 32)},
+		{KeyExchangeX25519, x25519Secret, append([]byte{1}, make([]byte, 31)...)},
+		// scalars 0 and n
+		{KeyExchangeP256, make([]byte, 32), p256Share},
+		{KeyExchangeP256, p256Order, p256Share},
+	} {
+		_, err := ECDHESharedSecret(c.kex, c.secret, c.share)
+		assert.Error(err)
+
+		circuit := &EcdheWrapper{
+			KeyExchange:  c.kex,
+			ClientSecret: make([]frontend.Variable, 32),
+			ServerShare:  make([]frontend.Variable, c.kex.ShareLen()),
+		}
+		assignment := &EcdheWrapper{
+			KeyExchange:  c.kex,
+			ClientSecret: toVariables(c.secret),
+			ServerShare:  toVariables(c.share),
+		}
+		for i := 0; i < 32; i++ {
+			assignment.SharedSecret[i] = 0
+		}
+
+		assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// witness-dependent offsets into the plaintext, one circuit serves
+// any position of substring and value within the plaintext. with a
+// substring match the value is the string value following it, substring
+// || ':"' || value || '"'
+type RecordOffsets struct {
+	SubstringStart frontend.Variable
+	SubstringEnd   frontend.Variable
+	ValueStart     frontend.Variable
+	ValueEnd       frontend.Variable
+	MaxValueLen    int
+}
+
+// extraction of bytes at in-circuit positions through a lookup table of the plaintext
+type Extractor struct {
+	api    frontend.API
+	table  *logderivlookup.Table
+	length int
+}
+
+// maxLen zero bytes are appended so that reads up to maxLen bytes
+// starting at any plaintext position stay inside the table
+func NewExtractor(api frontend.API, plaintext []frontend.Variable, maxLen int) Extractor {
+	table := logderivlookup.New(api)
+	for i := 0; i < len(plaintext); i++ {
+		table.Insert(plaintext[i])
+	}
+	for i := 0; i < maxLen; i++ {
+		table.Insert(0)
+	}
+	return Extractor{api: api, table: table, length: len(plaintext)}
+}
+
+// Substring returns length bytes starting at start
+func (e *Extractor) Substring(start frontend.Variable, length int) []frontend.Variable {
+	indices := make([]frontend.Variable, length)
+	for i := 0; i < length; i++ {
+		indices[i] = e.api.Add(start, i)
+	}
+	return e.table.Lookup(indices...)
+}
+
+// Value converts the digits in [start, end) to an integer, at most maxLen digits
+func (e *Extractor) Value(start, end frontend.Variable, maxLen int) frontend.Variable {
+
+	if maxLen > intDigits(e.api) {
+		panic("value length exceeds the digits of the field")
+	}
+
+	digits := e.Substring(start, maxLen)
+	length := e.api.Sub(end, start)
+
+	// digit i is part of the value while i < length, bytes past the
+	// value are replaced by '0' before the digit range check
+	active := make([]frontend.Variable, maxLen)
+	indices := make([]frontend.Variable, maxLen)
+	found := frontend.Variable(0)
+	for i := 0; i < maxLen; i++ {
+		found = e.api.Add(found, e.api.IsZero(e.api.Sub(length, i)))
+		active[i] = e.api.Sub(1, found)
+		indices[i] = e.api.Mul(active[i], e.api.Sub(digits[i], 48))
+	}
+	toInt := newDigitTable(e.api).Lookup(indices...)
+
+	sum := frontend.Variable(0)
+	for i := 0; i < maxLen; i++ {
+		next := e.api.MulAcc(toInt[i], sum, 10)
+		sum = e.api.Select(active[i], next, sum)
+	}
+	// value must have between one and maxLen digits and end inside the plaintext
+	found = e.api.Add(found, e.api.IsZero(e.api.Sub(length, maxLen)))
+	e.api.AssertIsEqual(found, 1)
+	e.api.AssertIsDifferent(length, 0)
+	e.api.AssertIsLessOrEqual(end, e.length)
+
+	return sum
+}
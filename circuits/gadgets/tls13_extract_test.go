@@ -0,0 +1,87 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordOffsets(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	substring := "\"price\""
+	maxValueLen := 8
+
+	// same record size, different layouts and value lengths
+	records := []struct {
+		plaintext string
+		value     string
+		valid     bool
+	}{
+		{`{"name":"bitcoin","price":"38002"} `, "38002", true},
+		{`{"price":"380025","name":"bitcoin"}`, "380025", true},
+		{`{"price":"37999","name":"bitcoin"} `, "37999", false},
+		// value offset at a number other than the price
+		{`{"price":"37999","vol":"99999999"}`, "99999999", false},
+	}
+
+	// one circuit definition for all layouts
+	plainLen := len(records[0].plaintext)
+	newCircuit := func() *RecordOffsetsWrapper {
+		return &RecordOffsetsWrapper{
+			PlainChunks:  make([]frontend.Variable, plainLen),
+			CipherChunks: make([]frontend.Variable, plainLen),
+			Substring:    make([]frontend.Variable, len(substring)),
+			MaxValueLen:  maxValueLen,
+		}
+	}
+
+	for _, record := range records {
+		substringStart := strings.Index(record.plaintext, substring)
+		valueStart := strings.Index(record.plaintext, record.value)
+
+		assignment := newCircuit()
+		assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, record.plaintext)
+		assignment.SequenceNumber = 0
+		assignment.ChunkIndex = 2
+		assignment.Threshold = 38000
+		assignment.SubstringStart = substringStart
+		assignment.SubstringEnd = substringStart + len(substring)
+		assignment.ValueStart = valueStart
+		assignment.ValueEnd = valueStart + len(record.value)
+		assignment.Substring = toVariables([]byte(substring))
+
+		if record.valid {
+			assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+			// substring offset pointing elsewhere must fail
+			assignment.SubstringStart = substringStart + 1
+			assignment.SubstringEnd = substringStart + 1 + len(substring)
+			assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		} else {
+			// value below threshold or not following the substring
+			assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
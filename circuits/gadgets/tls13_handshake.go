@@ -0,0 +1,211 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"golang.org/x/crypto/hkdf"
+)
+
+// handshake message types of rfc 8446
+const (
+	handshakeClientHello = 1
+	handshakeFinished    = 20
+)
+
+// length of a sha256 Finished message, type || uint24 length || verify_data
+const finishedMessageLen = 4 + 32
+
+// evaluate the key schedule over a handshake transcript
+type HandshakeWrapper struct {
+	CipherSuite         CipherSuite
+	SharedSecret        []frontend.Variable
+	Transcript          []frontend.Variable
+	ServerHelloEnd      int
+	ServerFinishedStart int
+	TranscriptHash      [32]frontend.Variable `gnark:",public"`
+	// optional key exchange replacing SharedSecret
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable   `gnark:",public"`
+	TkSAPP       []frontend.Variable   `gnark:",public"`
+	IvSAPP       [12]frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *HandshakeWrapper) Define(api frontend.API) error {
+
+	sharedSecret := circuit.SharedSecret
+	if circuit.KeyExchange != KeyExchangeNone {
+		var err error
+		sharedSecret, err = ECDHE(api, circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+		if err != nil {
+			return err
+		}
+	}
+
+	handshake := NewTls13Handshake(api)
+	handshake.SetCipherSuite(circuit.CipherSuite)
+	handshake.SetParams(sharedSecret, circuit.Transcript, circuit.ServerHelloEnd, circuit.ServerFinishedStart)
+	handshake.SetTranscriptHash(circuit.TranscriptHash[:])
+
+	tk, iv, err := handshake.Derive()
+	if err != nil {
+		return err
+	}
+
+	if len(tk) != len(circuit.TkSAPP) {
+		return errors.New("traffic key length does not match the cipher suite")
+	}
+	for i := 0; i < len(tk); i++ {
+		api.AssertIsEqual(tk[i], circuit.TkSAPP[i])
+	}
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(iv[i], circuit.IvSAPP[i])
+	}
+
+	return nil
+}
+
+// key schedule of rfc 8446 computed from the (ec)dhe shared secret and the
+// handshake transcript. the transcript holds the handshake messages from
+// ClientHello to the server Finished without record headers, offsets are
+// fixed at circuit build time
+type Tls13Handshake struct {
+	api                 frontend.API
+	CipherSuite         CipherSuite
+	SharedSecret        []frontend.Variable
+	Transcript          []frontend.Variable
+	ServerHelloEnd      int
+	ServerFinishedStart int
+	TranscriptHash      []frontend.Variable // `gnark:",public"`
+}
+
+func NewTls13Handshake(api frontend.API) Tls13Handshake {
+	return Tls13Handshake{api: api}
+}
+
+// ServerHelloEnd is the transcript offset after the ServerHello, the server
+// Finished message must close the transcript
+func (circuit *Tls13Handshake) SetParams(sharedSecret, transcript []frontend.Variable, serverHelloEnd, serverFinishedStart int) {
+	circuit.SharedSecret = sharedSecret
+	circuit.Transcript = transcript
+	circuit.ServerHelloEnd = serverHelloEnd
+	circuit.ServerFinishedStart = serverFinishedStart
+}
+
+// optional public hash of the transcript up to the server Finished, it
+// fixes the key shares and the server Finished the traffic keys derive from
+func (circuit *Tls13Handshake) SetTranscriptHash(transcriptHash []frontend.Variable) {
+	circuit.TranscriptHash = transcriptHash
+}
+
+// defaults to TLS_AES_128_GCM_SHA256
+func (circuit *Tls13Handshake) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
+// Derive verifies the server Finished mac and returns the server application
+// traffic key and iv
+func (circuit *Tls13Handshake) Derive() ([]frontend.Variable, []frontend.Variable, error) {
+
+	api := circuit.api
+	cs := circuit.CipherSuite
+
+	if cs.HashLen() != 32 {
+		return nil, nil, errors.New("handshake verification supports sha256 cipher suites only")
+	}
+	if circuit.ServerHelloEnd <= 0 || circuit.ServerHelloEnd > circuit.ServerFinishedStart ||
+		circuit.ServerFinishedStart+finishedMessageLen != len(circuit.Transcript) {
+		return nil, nil, errors.New("invalid handshake transcript offsets")
+	}
+	if circuit.TranscriptHash != nil && len(circuit.TranscriptHash) != 32 {
+		return nil, nil, errors.New("transcript hash must have 32 bytes")
+	}
+
+	transcript := circuit.Transcript
+	finished := transcript[circuit.ServerFinishedStart:]
+
+	// message framing
+	api.AssertIsEqual(transcript[0], handshakeClientHello)
+	api.AssertIsEqual(finished[0], handshakeFinished)
+	api.AssertIsEqual(finished[1], 0)
+	api.AssertIsEqual(finished[2], 0)
+	api.AssertIsEqual(finished[3], 32)
+
+	// running transcript hash, Sum does not consume the digest
+	sha := NewSHA256(api)
+	sha.Write(transcript[:circuit.ServerHelloEnd])
+	helloHash := sha.Sum()
+	sha.Write(transcript[circuit.ServerHelloEnd:circuit.ServerFinishedStart])
+	verifyHash := sha.Sum()
+	sha.Write(finished)
+	finishedHash := sha.Sum()
+	for i := 0; i < len(circuit.TranscriptHash); i++ {
+		api.AssertIsEqual(finishedHash[i], circuit.TranscriptHash[i])
+	}
+
+	// handshake secret, the early secret without psk is a constant
+	emptyHash := sha256.Sum256(nil)
+	hs := HKDFExtract(api, constVariables(earlyDerivedSecret()), circuit.SharedSecret)
+	sHS := DeriveSecret(api, hs[:], "s hs traffic", helloHash[:])
+
+	// server Finished
+	finishedKey := HKDFExpandLabel(api, sHS, "finished", nil, 32)
+	verifyData := HMAC(api, finishedKey, verifyHash[:])
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(finished[4+i], verifyData[i])
+	}
+
+	// master secret and server application traffic secret
+	derived := DeriveSecret(api, hs[:], "derived", constVariables(emptyHash[:]))
+	ms := HKDFExtract(api, derived, constVariables(make([]byte, 32)))
+	sAP := DeriveSecret(api, ms[:], "s ap traffic", finishedHash[:])
+
+	tk := HKDFExpandLabel(api, sAP, "key", nil, cs.KeyLen())
+	iv := HKDFExpandLabel(api, sAP, "iv", nil, 12)
+
+	return tk, iv, nil
+}
+
+// Derive-Secret(HKDF-Extract(0, 0), "derived", "") of sha256, the salt of the
+// handshake secret when no psk is used
+func earlyDerivedSecret() []byte {
+	emptyHash := sha256.Sum256(nil)
+	early := hkdf.Extract(sha256.New, make([]byte, 32), make([]byte, 32))
+	label := "tls13 derived"
+	info := []byte{0, 32, byte(len(label))}
+	info = append(info, label...)
+	info = append(info, byte(len(emptyHash)))
+	info = append(info, emptyHash[:]...)
+	out := make([]byte, 32)
+	if _, err := hkdf.Expand(sha256.New, early, info).Read(out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func constVariables(in []byte) []frontend.Variable {
+	out := make([]frontend.Variable, len(in))
+	for i := range in {
+		out[i] = in[i]
+	}
+	return out
+}
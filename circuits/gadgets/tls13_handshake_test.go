@@ -0,0 +1,130 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/hkdf"
+)
+
+// handshake message of type msgType with a filler body
+func handshakeMessage(msgType byte, bodyLen int) []byte {
+	msg := []byte{msgType, byte(bodyLen >> 16), byte(bodyLen >> 8), byte(bodyLen)}
+	for i := 0; i < bodyLen; i++ {
+		msg = append(msg, byte(i*7+int(msgType)))
+	}
+	return msg
+}
+
+// synthetic handshake transcript closed by a valid server Finished, returns
+// the offsets and the server application traffic key and iv
+func handshakeTranscript(sharedSecret []byte) ([]byte, int, int, []byte, []byte) {
+
+	// ClientHello, ServerHello, EncryptedExtensions, Certificate, CertificateVerify
+	var transcript []byte
+	transcript = append(transcript, handshakeMessage(1, 120)...)
+	transcript = append(transcript, handshakeMessage(2, 86)...)
+	serverHelloEnd := len(transcript)
+	transcript = append(transcript, handshakeMessage(8, 6)...)
+	transcript = append(transcript, handshakeMessage(11, 180)...)
+	transcript = append(transcript, handshakeMessage(15, 74)...)
+	serverFinishedStart := len(transcript)
+
+	// out of circuit key schedule
+	helloHash := sha256.Sum256(transcript[:serverHelloEnd])
+	hs := hkdf.Extract(sha256.New, sharedSecret, earlyDerivedSecret())
+	sHS := expandLabel(hs, "s hs traffic", helloHash[:], 32)
+	finishedKey := expandLabel(sHS, "finished", nil, 32)
+	verifyHash := sha256.Sum256(transcript)
+	mac := hmac.New(sha256.New, finishedKey)
+	mac.Write(verifyHash[:])
+	transcript = append(transcript, 20, 0, 0, 32)
+	transcript = append(transcript, mac.Sum(nil)...)
+
+	tk, iv := applicationKeys(sharedSecret, transcript)
+	return transcript, serverHelloEnd, serverFinishedStart, tk, iv
+}
+
+// server application traffic key and iv of a transcript, the Finished mac is not checked
+func applicationKeys(sharedSecret, transcript []byte) ([]byte, []byte) {
+	hs := hkdf.Extract(sha256.New, sharedSecret, earlyDerivedSecret())
+	emptyHash := sha256.Sum256(nil)
+	derived := expandLabel(hs, "derived", emptyHash[:], 32)
+	ms := hkdf.Extract(sha256.New, make([]byte, 32), derived)
+	finishedHash := sha256.Sum256(transcript)
+	sAP := expandLabel(ms, "s ap traffic", finishedHash[:], 32)
+	return expandLabel(sAP, "key", nil, 16), expandLabel(sAP, "iv", nil, 12)
+}
+
+func TestHandshake(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	sharedSecret := mustHex("8bd4054fb55b9d63fdfbacf9f04b9f0d35e6d63f537563efd46272900f89492d")
+	transcript, serverHelloEnd, serverFinishedStart, _, _ := handshakeTranscript(sharedSecret)
+
+	newCircuit := func() *HandshakeWrapper {
+		return &HandshakeWrapper{
+			SharedSecret:        make([]frontend.Variable, len(sharedSecret)),
+			Transcript:          make([]frontend.Variable, len(transcript)),
+			ServerHelloEnd:      serverHelloEnd,
+			ServerFinishedStart: serverFinishedStart,
+			TkSAPP:              make([]frontend.Variable, 16),
+		}
+	}
+	// keys derived from the given secret and transcript, so that only the
+	// Finished mac can reject a modified input
+	newAssignment := func(sharedSecret, transcript []byte) *HandshakeWrapper {
+		tk, iv := applicationKeys(sharedSecret, transcript)
+		assignment := newCircuit()
+		assignment.SharedSecret = toVariables(sharedSecret)
+		assignment.Transcript = toVariables(transcript)
+		transcriptHash := sha256.Sum256(transcript)
+		for i := 0; i < 32; i++ {
+			assignment.TranscriptHash[i] = transcriptHash[i]
+		}
+		assignment.TkSAPP = toVariables(tk)
+		for i := 0; i < 12; i++ {
+			assignment.IvSAPP[i] = iv[i]
+		}
+		return assignment
+	}
+
+	assert.SolvingSucceeded(newCircuit(), newAssignment(sharedSecret, transcript), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a modified certificate with the stale Finished breaks the Finished mac
+	tampered := append([]byte{}, transcript...)
+	tampered[serverHelloEnd+20] ^= 1
+	assert.SolvingFailed(newCircuit(), newAssignment(sharedSecret, tampered), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a wrong shared secret breaks the Finished mac
+	wrongSecret := append([]byte{}, sharedSecret...)
+	wrongSecret[0] ^= 1
+	assert.SolvingFailed(newCircuit(), newAssignment(wrongSecret, transcript), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// the public transcript hash must match
+	assignment := newAssignment(sharedSecret, transcript)
+	transcriptHash := sha256.Sum256(transcript)
+	assignment.TranscriptHash[0] = transcriptHash[0] ^ 1
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
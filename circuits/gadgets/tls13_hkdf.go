@@ -0,0 +1,126 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// evaluate hkdf extract followed by expand label
+type HkdfWrapper struct {
+	Salt    []frontend.Variable `gnark:",public"`
+	Ikm     []frontend.Variable
+	Label   string
+	Context []frontend.Variable `gnark:",public"`
+	Out     []frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *HkdfWrapper) Define(api frontend.API) error {
+
+	prk := HKDFExtract(api, circuit.Salt, circuit.Ikm)
+	out := HKDFExpandLabel(api, prk[:], circuit.Label, circuit.Context, len(circuit.Out))
+
+	for i := 0; i < len(circuit.Out); i++ {
+		api.AssertIsEqual(out[i], circuit.Out[i])
+	}
+
+	return nil
+}
+
+// HMAC computes hmac-sha256 of msg, keys longer than the block size are hashed first
+func HMAC(api frontend.API, key, msg []frontend.Variable) [32]frontend.Variable {
+
+	if len(key) > 64 {
+		sha := NewSHA256(api)
+		sha.Write(key)
+		sum := sha.Sum()
+		key = sum[:]
+	}
+
+	// inner hash
+	inner := NewSHA256(api)
+	inner.Write(PadXor(api, key, 0x36, 64))
+	inner.Write(msg)
+	innerSum := inner.Sum()
+
+	// outer hash
+	outer := NewSHA256(api)
+	outer.Write(PadXor(api, key, 0x5c, 64))
+	outer.Write(innerSum[:])
+
+	return outer.Sum()
+}
+
+// HKDFExtract returns the pseudorandom key of rfc 5869, HMAC(salt, ikm)
+func HKDFExtract(api frontend.API, salt, ikm []frontend.Variable) [32]frontend.Variable {
+	return HMAC(api, salt, ikm)
+}
+
+// HKDFExpand returns length bytes of output keying material of rfc 5869
+func HKDFExpand(api frontend.API, prk, info []frontend.Variable, length int) []frontend.Variable {
+
+	if length > 255*32 {
+		panic("hkdf expand length exceeds 255 hash lengths")
+	}
+
+	var out []frontend.Variable
+	var t []frontend.Variable
+	for counter := 1; len(out) < length; counter++ {
+		// T(n) = HMAC(prk, T(n-1) || info || n)
+		var msg []frontend.Variable
+		msg = append(msg, t...)
+		msg = append(msg, info...)
+		msg = append(msg, counter)
+		sum := HMAC(api, prk, msg)
+		t = sum[:]
+		out = append(out, t...)
+	}
+
+	return out[:length]
+}
+
+// HKDFExpandLabel of rfc 8446, the HkdfLabel structure is built in-circuit from
+// the output length, the label prefixed with "tls13 " and the context bytes
+func HKDFExpandLabel(api frontend.API, secret []frontend.Variable, label string, context []frontend.Variable, length int) []frontend.Variable {
+	return HKDFExpand(api, secret, HkdfLabel(label, context, length), length)
+}
+
+// DeriveSecret of rfc 8446 with the transcript hash as context
+func DeriveSecret(api frontend.API, secret []frontend.Variable, label string, transcriptHash []frontend.Variable) []frontend.Variable {
+	return HKDFExpandLabel(api, secret, label, transcriptHash, 32)
+}
+
+// HkdfLabel serializes uint16 length || opaque label<7..255> || opaque context<0..255>
+func HkdfLabel(label string, context []frontend.Variable, length int) []frontend.Variable {
+
+	fullLabel := "tls13 " + label
+	if len(fullLabel) > 255 || len(context) > 255 {
+		panic("hkdf label or context exceeds 255 bytes")
+	}
+
+	var out []frontend.Variable
+	out = append(out, (length>>8)&0xff, length&0xff)
+	out = append(out, len(fullLabel))
+	for i := 0; i < len(fullLabel); i++ {
+		out = append(out, int(fullLabel[i]))
+	}
+	out = append(out, len(context))
+	out = append(out, context...)
+
+	return out
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/hkdf"
+)
+
+// hmac wrapper
+type hmacWrapper struct {
+	Key []frontend.Variable
+	Msg []frontend.Variable
+	Mac [32]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *hmacWrapper) Define(api frontend.API) error {
+	mac := HMAC(api, circuit.Key, circuit.Msg)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(mac[i], circuit.Mac[i])
+	}
+	return nil
+}
+
+// out of circuit hkdf expand label
+func expandLabel(secret []byte, label string, context []byte, length int) []byte {
+	fullLabel := "tls13 " + label
+	info := []byte{byte(length >> 8), byte(length), byte(len(fullLabel))}
+	info = append(info, fullLabel...)
+	info = append(info, byte(len(context)))
+	info = append(info, context...)
+	out := make([]byte, length)
+	if _, err := hkdf.Expand(sha256.New, secret, info).Read(out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func TestHMAC(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// short and long keys, long keys are hashed first
+	for _, keyLen := range []int{32, 100} {
+		key := make([]byte, keyLen)
+		for i := range key {
+			key[i] = byte(i)
+		}
+		msg := []byte("tls13 hmac gadget test message")
+
+		h := hmac.New(sha256.New, key)
+		h.Write(msg)
+		mac := h.Sum(nil)
+
+		assignment := hmacWrapper{Key: toVariables(key), Msg: toVariables(msg)}
+		for i := 0; i < 32; i++ {
+			assignment.Mac[i] = mac[i]
+		}
+		circuit := hmacWrapper{Key: make([]frontend.Variable, keyLen), Msg: make([]frontend.Variable, len(msg))}
+		assert.SolvingSucceeded(&circuit, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestHKDFExpandLabel(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// rfc 8448 simple 1-rtt handshake, derived secret of the early secret
+	emptyHash := sha256.Sum256(nil)
+	derived := mustHex("6f2615a108c702c5678f54fc9dbab69716c076189c48250cebeac3576c3611ba")
+
+	newCircuit := func(label string, contextLen, outLen int) *HkdfWrapper {
+		return &HkdfWrapper{
+			Salt:    make([]frontend.Variable, 32),
+			Ikm:     make([]frontend.Variable, 32),
+			Label:   label,
+			Context: make([]frontend.Variable, contextLen),
+			Out:     make([]frontend.Variable, outLen),
+		}
+	}
+
+	assignment := HkdfWrapper{
+		Salt:    toVariables(make([]byte, 32)),
+		Ikm:     toVariables(make([]byte, 32)),
+		Context: toVariables(emptyHash[:]),
+		Out:     toVariables(derived),
+	}
+	assert.SolvingSucceeded(newCircuit("derived", 32, 32), &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// the label is part of the circuit, a different label must fail
+	assert.SolvingFailed(newCircuit("c hs traffic", 32, 32), &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// traffic key with empty context and output longer than one hash length
+	salt := derived
+	ikm := sha256.Sum256([]byte("shared secret"))
+	prk := hkdf.Extract(sha256.New, ikm[:], salt)
+	for _, outLen := range []int{16, 48} {
+		out := expandLabel(prk, "key", nil, outLen)
+		assignment := HkdfWrapper{
+			Salt:    toVariables(salt),
+			Ikm:     toVariables(ikm[:]),
+			Context: []frontend.Variable{},
+			Out:     toVariables(out),
+		}
+		assert.SolvingSucceeded(newCircuit("key", 0, outLen), &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
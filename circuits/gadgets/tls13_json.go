@@ -0,0 +1,173 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// maximum nesting depth of json documents
+const jsonMaxDepth = 31
+
+// json scanner over a plaintext, tracks per byte the nesting depth and
+// string and escape state before the byte. key paths are checked against
+// the state so that keys cannot be matched inside strings or sibling objects.
+// documents are expected in compact form, i.e. "key":value without whitespace.
+type JsonScanner struct {
+	api         frontend.API
+	depth       []frontend.Variable
+	inString    []frontend.Variable
+	terminator  []frontend.Variable
+	extractor   Extractor
+	depthTable  *logderivlookup.Table
+	stringTable *logderivlookup.Table
+}
+
+func NewJsonScanner(api frontend.API, plaintext []frontend.Variable, maxKeyLen int) JsonScanner {
+
+	n := len(plaintext)
+	depth := make([]frontend.Variable, n+1)
+	inString := make([]frontend.Variable, n+1)
+	terminator := make([]frontend.Variable, n)
+	depth[0] = 0
+	inString[0] = 0
+	escaped := frontend.Variable(0)
+
+	for i := 0; i < n; i++ {
+		c := plaintext[i]
+		isQuote := api.IsZero(api.Sub(c, '"'))
+		isBackslash := api.IsZero(api.Sub(c, '\\'))
+		isOpen := api.Add(api.IsZero(api.Sub(c, '{')), api.IsZero(api.Sub(c, '[')))
+		isClose := api.Add(api.IsZero(api.Sub(c, '}')), api.IsZero(api.Sub(c, ']')))
+
+		// unescaped quotes toggle the string state
+		toggle := api.Mul(isQuote, api.Sub(1, escaped))
+		inString[i+1] = api.Xor(inString[i], toggle)
+
+		// a backslash inside a string escapes the next byte
+		escaped = api.Mul(inString[i], api.Sub(1, escaped), isBackslash)
+
+		// brackets outside strings change the depth
+		depth[i+1] = api.Add(depth[i], api.Mul(api.Sub(1, inString[i]), api.Sub(isOpen, isClose)))
+
+		// bytes ending non-string values
+		terminator[i] = api.Add(api.IsZero(api.Sub(c, ',')), isClose)
+	}
+
+	depthTable := logderivlookup.New(api)
+	stringTable := logderivlookup.New(api)
+	for i := 0; i <= n; i++ {
+		depthTable.Insert(depth[i])
+		stringTable.Insert(inString[i])
+	}
+
+	return JsonScanner{
+		api:         api,
+		depth:       depth,
+		inString:    inString,
+		terminator:  terminator,
+		extractor:   NewExtractor(api, plaintext, maxKeyLen+3),
+		depthTable:  depthTable,
+		stringTable: stringTable,
+	}
+}
+
+// AssertKeyPath checks that the value of path sits at [valueStart, valueEnd).
+// keyStarts holds the position of the opening quote of each key in path.
+// string values exclude their quotes and end at the first unescaped quote,
+// other values end at the first ',', '}' or ']'.
+func (j *JsonScanner) AssertKeyPath(path []string, keyStarts []frontend.Variable, valueStart, valueEnd frontend.Variable) {
+
+	api := j.api
+	if len(path) != len(keyStarts) {
+		panic("key path and key positions differ in length")
+	}
+
+	var objectStart frontend.Variable
+	for k, key := range path {
+
+		// "key": at the claimed position
+		token := j.extractor.Substring(keyStarts[k], len(key)+3)
+		expected := "\"" + key + "\":"
+		for i := 0; i < len(expected); i++ {
+			api.AssertIsEqual(token[i], int(expected[i]))
+		}
+
+		// key starts outside of strings at nesting depth k+1
+		state := j.stringTable.Lookup(keyStarts[k])
+		api.AssertIsEqual(state[0], 0)
+		depth := j.depthTable.Lookup(keyStarts[k])
+		api.AssertIsEqual(depth[0], k+1)
+
+		// nested keys sit inside the value of the parent key, meaning the
+		// depth does not fall below k+1 between parent value and key
+		if k > 0 {
+			j.assertMinDepth(objectStart, keyStarts[k], k+1)
+		}
+		objectStart = api.Add(keyStarts[k], len(key)+3)
+	}
+
+	// string values start after their opening quote
+	first := j.extractor.Substring(objectStart, 1)
+	isString := api.IsZero(api.Sub(first[0], '"'))
+	api.AssertIsEqual(valueStart, api.Add(objectStart, isString))
+
+	// value terminator
+	last := j.extractor.Substring(valueEnd, 1)
+	c := last[0]
+	api.AssertIsEqual(api.Mul(isString, api.Sub(c, '"')), 0)
+	notString := api.Sub(1, isString)
+	api.AssertIsEqual(api.Mul(notString, api.Sub(c, ','), api.Sub(c, '}'), api.Sub(c, ']')), 0)
+
+	// the terminator of a string value closes the string
+	state := j.stringTable.Lookup(valueEnd, api.Add(valueEnd, 1))
+	api.AssertIsEqual(api.Mul(isString, api.Sub(1, state[0])), 0)
+	api.AssertIsEqual(api.Mul(isString, state[1]), 0)
+
+	// and the terminator is the first one after valueStart
+	inRange := frontend.Variable(0)
+	for i := 0; i < len(j.terminator); i++ {
+		inRange = api.Add(inRange, api.IsZero(api.Sub(valueStart, i)))
+		inRange = api.Sub(inRange, api.IsZero(api.Sub(valueEnd, i)))
+		api.AssertIsBoolean(inRange)
+
+		// strings stay open, other values hold no terminator
+		api.AssertIsEqual(api.Mul(inRange, isString, api.Sub(1, j.inString[i])), 0)
+		api.AssertIsEqual(api.Mul(inRange, notString, j.terminator[i]), 0)
+	}
+}
+
+// asserts from <= to and depth >= minDepth for all positions in [from, to)
+func (j *JsonScanner) assertMinDepth(from, to frontend.Variable, minDepth int) {
+
+	api := j.api
+	nbBits := bits.Len(uint(jsonMaxDepth))
+
+	inRange := frontend.Variable(0)
+	for i := 0; i < len(j.depth)-1; i++ {
+		inRange = api.Add(inRange, api.IsZero(api.Sub(from, i)))
+		inRange = api.Sub(inRange, api.IsZero(api.Sub(to, i)))
+		// to before from leaves -1 in between
+		api.AssertIsBoolean(inRange)
+
+		// difference must be a small non-negative number inside the range
+		api.ToBinary(api.Mul(inRange, api.Sub(j.depth[i+1], minDepth)), nbBits)
+	}
+}
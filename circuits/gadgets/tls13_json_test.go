@@ -0,0 +1,166 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordJson(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	path := []string{"data", "account", "balance"}
+
+	// decoy balances inside a key string and inside a sibling object,
+	// brackets inside strings must not change the depth
+	plaintext := `{"x":{"balance":"99999"},"data":{"account":{"memo":"}]","x\"balance":"99999","balance":"38002"}}}`
+	decoyString := strings.Index(plaintext, `\"balance"`) + 1
+	decoySibling := strings.Index(plaintext, `"balance"`)
+	dataStart := strings.Index(plaintext, `"data"`)
+	accountStart := strings.Index(plaintext, `"account"`)
+	balanceStart := strings.LastIndex(plaintext, `"balance"`)
+	valueStart := strings.Index(plaintext, "38002")
+
+	newCircuit := func() *RecordJsonWrapper {
+		return &RecordJsonWrapper{
+			PlainChunks:  make([]frontend.Variable, len(plaintext)),
+			CipherChunks: make([]frontend.Variable, len(plaintext)),
+			KeyPath:      path,
+			KeyStarts:    make([]frontend.Variable, len(path)),
+			MaxValueLen:  8,
+		}
+	}
+
+	assignment := newCircuit()
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 2
+	assignment.Threshold = 38000
+	assignment.KeyStarts = []frontend.Variable{dataStart, accountStart, balanceStart}
+	assignment.ValueStart = valueStart
+	assignment.ValueEnd = valueStart + 5
+	assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, plaintext)
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// escaped quote inside a key string followed by a matching "balance": token must fail
+	assignment.KeyStarts = []frontend.Variable{dataStart, accountStart, decoyString}
+	assignment.ValueStart = decoyString + len(`"balance":"`)
+	assignment.ValueEnd = assignment.ValueStart.(int) + 5
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// key of a sibling object must fail
+	assignment.KeyStarts = []frontend.Variable{dataStart, accountStart, decoySibling}
+	assignment.ValueStart = decoySibling + len(`"balance":"`)
+	assignment.ValueEnd = assignment.ValueStart.(int) + 5
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestRecordJsonDecoyBeforeParent(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	path := []string{"data", "account", "balance"}
+
+	// depth matched decoy ahead of the parent keys
+	plaintext := `{"x":{"y":{"balance":"99999"}},"data":{"account":{"balance":"1"}}}`
+	dataStart := strings.Index(plaintext, `"data"`)
+	accountStart := strings.Index(plaintext, `"account"`)
+	decoyStart := strings.Index(plaintext, `"balance"`)
+	valueStart := strings.Index(plaintext, "99999")
+
+	newCircuit := func() *RecordJsonWrapper {
+		return &RecordJsonWrapper{
+			PlainChunks:  make([]frontend.Variable, len(plaintext)),
+			CipherChunks: make([]frontend.Variable, len(plaintext)),
+			KeyPath:      path,
+			KeyStarts:    make([]frontend.Variable, len(path)),
+			MaxValueLen:  8,
+		}
+	}
+
+	assignment := newCircuit()
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 2
+	assignment.Threshold = 38000
+	assignment.KeyStarts = []frontend.Variable{dataStart, accountStart, decoyStart}
+	assignment.ValueStart = valueStart
+	assignment.ValueEnd = valueStart + 5
+	assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, plaintext)
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+// json scanner without value parsing
+type jsonPathWrapper struct {
+	Plaintext  []frontend.Variable
+	KeyPath    []string
+	KeyStarts  []frontend.Variable
+	ValueStart frontend.Variable
+	ValueEnd   frontend.Variable
+}
+
+func (circuit *jsonPathWrapper) Define(api frontend.API) error {
+	scanner := NewJsonScanner(api, circuit.Plaintext, 1)
+	scanner.AssertKeyPath(circuit.KeyPath, circuit.KeyStarts, circuit.ValueStart, circuit.ValueEnd)
+	return nil
+}
+
+func TestJsonValueEnd(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	plaintext := `{"a":"x\"y","b":7,"c":"z"}`
+	values := []struct {
+		key        string
+		valueStart int
+		valueEnd   int
+		valid      bool
+	}{
+		// closing quote of "a"
+		{"a", 6, 10, true},
+		// escaped quote inside "a"
+		{"a", 6, 8, false},
+		// opening quote of the next key
+		{"a", 6, 12, false},
+		// comma after 7
+		{"b", 16, 17, true},
+		// closing brace after a later comma
+		{"b", 16, 25, false},
+	}
+
+	for _, v := range values {
+		circuit := &jsonPathWrapper{
+			Plaintext: make([]frontend.Variable, len(plaintext)),
+			KeyPath:   []string{v.key},
+			KeyStarts: make([]frontend.Variable, 1),
+		}
+		assignment := &jsonPathWrapper{
+			Plaintext:  toVariables([]byte(plaintext)),
+			KeyPath:    []string{v.key},
+			KeyStarts:  []frontend.Variable{strings.Index(plaintext, `"`+v.key+`"`)},
+			ValueStart: v.valueStart,
+			ValueEnd:   v.valueEnd,
+		}
+		if v.valid {
+			assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+}
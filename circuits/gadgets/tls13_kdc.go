@@ -17,6 +17,8 @@ limitations under the License.
 package gadgets
 
 import (
+	"errors"
+
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -26,81 +28,155 @@ type KdcWrapper struct {
 	MSin                   [32]frontend.Variable `gnark:",public"`
 	XATSin                 [32]frontend.Variable `gnark:",public"`
 	TkXAPPin               [32]frontend.Variable `gnark:",public"`
+	IvXAPPin               [32]frontend.Variable `gnark:",public"`
 	TkXAPP                 [16]frontend.Variable `gnark:",public"`
+	IvXAPP                 [12]frontend.Variable `gnark:",public"`
 }
 
 func (circuit *KdcWrapper) Define(api frontend.API) error {
 
 	tls13_kdc := NewTls13Kdc(api)
 	tls13_kdc.SetParams(
-		circuit.IntermediateHashHSopad,
-		circuit.MSin,
-		circuit.XATSin,
-		circuit.TkXAPPin,
-		circuit.DHSin,
+		circuit.IntermediateHashHSopad[:],
+		circuit.MSin[:],
+		circuit.XATSin[:],
+		circuit.TkXAPPin[:],
+		circuit.IvXAPPin[:],
+		circuit.DHSin[:],
 	)
-	tk := tls13_kdc.Derive()
+	tk, iv, err := tls13_kdc.Derive()
+	if err != nil {
+		return err
+	}
 
 	for i := 0; i < 16; i++ {
 		api.AssertIsEqual(tk[i], circuit.TkXAPP[i])
 	}
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(iv[i], circuit.IvXAPP[i])
+	}
+
+	return nil
+}
+
+// kdc starting from an in-circuit key exchange instead of DHSin
+type KdcEcdheWrapper struct {
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable   `gnark:",public"`
+	MSin         [32]frontend.Variable `gnark:",public"`
+	XATSin       [32]frontend.Variable `gnark:",public"`
+	TkXAPPin     [32]frontend.Variable `gnark:",public"`
+	IvXAPPin     [32]frontend.Variable `gnark:",public"`
+	TkXAPP       [16]frontend.Variable `gnark:",public"`
+	IvXAPP       [12]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *KdcEcdheWrapper) Define(api frontend.API) error {
+
+	sharedSecret, err := ECDHE(api, circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+	if err != nil {
+		return err
+	}
+
+	tls13_kdc := NewTls13Kdc(api)
+	tls13_kdc.SetParams(nil, circuit.MSin[:], circuit.XATSin[:], circuit.TkXAPPin[:], circuit.IvXAPPin[:], nil)
+	tls13_kdc.SetSharedSecret(sharedSecret)
+	tk, iv, err := tls13_kdc.Derive()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < 16; i++ {
+		api.AssertIsEqual(tk[i], circuit.TkXAPP[i])
+	}
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(iv[i], circuit.IvXAPP[i])
+	}
 
 	return nil
 }
 
 type Tls13Kdc struct {
 	api                    frontend.API
-	DHSin                  [64]frontend.Variable
-	IntermediateHashHSopad [32]frontend.Variable // `gnark:",public"`
-	MSin                   [32]frontend.Variable // `gnark:",public"`
-	XATSin                 [32]frontend.Variable // `gnark:",public"`
-	TkXAPPin               [32]frontend.Variable // `gnark:",public"`
+	CipherSuite            CipherSuite
+	SharedSecret           []frontend.Variable
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable // `gnark:",public"`
+	MSin                   []frontend.Variable // `gnark:",public"`
+	XATSin                 []frontend.Variable // `gnark:",public"`
+	TkXAPPin               []frontend.Variable // `gnark:",public"`
+	IvXAPPin               []frontend.Variable // `gnark:",public"`
 }
 
 func NewTls13Kdc(api frontend.API) Tls13Kdc {
 	return Tls13Kdc{api: api}
 }
 
-func (circuit *Tls13Kdc) SetParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin [32]frontend.Variable, DHSin [64]frontend.Variable) {
+// intermediate hash inputs have hash length, IntermediateHashHSopad is the hash state
+// after the opad block and DHSin a single padded block of the cipher suite hash
+func (circuit *Tls13Kdc) SetParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin, IvXAPPin, DHSin []frontend.Variable) {
 	circuit.DHSin = DHSin
 	circuit.IntermediateHashHSopad = IntermediateHashHSopad
 	circuit.MSin = MSin
 	circuit.XATSin = XATSin
 	circuit.TkXAPPin = TkXAPPin
+	circuit.IvXAPPin = IvXAPPin
 }
 
-// Define declares the circuit's constraints
-func (circuit *Tls13Kdc) Derive() []frontend.Variable {
+// optional (ec)dhe shared secret, the handshake secret is then computed with
+// HKDF-Extract and IntermediateHashHSopad and DHSin are not used
+func (circuit *Tls13Kdc) SetSharedSecret(sharedSecret []frontend.Variable) {
+	circuit.SharedSecret = sharedSecret
+}
 
-	// gadget imports
-	sha := NewSHA256(circuit.api)
+// defaults to TLS_AES_128_GCM_SHA256
+func (circuit *Tls13Kdc) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
 
-	// optimized shacal2
-	shacal := NewSHA256WithIV(circuit.api, circuit.IntermediateHashHSopad, 64)
-	dHS := shacal.WriteReturn(circuit.DHSin[:])
+// Define declares the circuit's constraints, returns traffic key and iv
+func (circuit *Tls13Kdc) Derive() ([]frontend.Variable, []frontend.Variable, error) {
+
+	cs := circuit.CipherSuite
+	blockLen := cs.BlockLen()
+
+	var dHS []frontend.Variable
+	if circuit.SharedSecret != nil {
+		// handshake secret from the shared secret, the salt is a constant
+		if cs.HashLen() != 32 {
+			return nil, nil, errors.New("shared secret key schedule supports sha256 cipher suites only")
+		}
+		hs := HKDFExtract(circuit.api, constVariables(earlyDerivedSecret()), circuit.SharedSecret)
+		dHS = hs[:]
+	} else {
+		// optimized shacal2
+		dHS = cs.sumWithIV(circuit.api, circuit.IntermediateHashHSopad, circuit.DHSin)
+	}
 
 	// dHS xor opad, and concatenate with MSIn
-	dHSopadConcatMSin := OpadConcat(circuit.api, dHS, circuit.MSin)
+	dHSopadConcatMSin := OpadConcatN(circuit.api, dHS, circuit.MSin, blockLen)
 
 	// compute MS
-	sha.Write(dHSopadConcatMSin)
-	MS := sha.Sum()
-	sha.Reset()
+	MS := cs.sum(circuit.api, dHSopadConcatMSin)
 
 	// MS xor opad, and concatenate with XATSin
-	MSopadConcatXATSin := OpadConcat(circuit.api, MS, circuit.XATSin)
+	MSopadConcatXATSin := OpadConcatN(circuit.api, MS, circuit.XATSin, blockLen)
 
 	// compute XATS
-	sha.Write(MSopadConcatXATSin)
-	XATS := sha.Sum()
-	sha.Reset()
+	XATS := cs.sum(circuit.api, MSopadConcatXATSin)
 
 	// XATS xor opad, and concatenate with tkXAPPin
-	XATSopadConcattkXAPPin := OpadConcat(circuit.api, XATS, circuit.TkXAPPin)
+	XATSopadConcattkXAPPin := OpadConcatN(circuit.api, XATS, circuit.TkXAPPin, blockLen)
 
 	// traffic key
-	sha.Write(XATSopadConcattkXAPPin)
-	tkXAPP := sha.Sum()
+	tkXAPP := cs.sum(circuit.api, XATSopadConcattkXAPPin)
+
+	// XATS xor opad, and concatenate with ivXAPPin
+	XATSopadConcativXAPPin := OpadConcatN(circuit.api, XATS, circuit.IvXAPPin, blockLen)
+
+	// traffic iv
+	ivXAPP := cs.sum(circuit.api, XATSopadConcativXAPPin)
 
-	return tkXAPP[:16]
+	return tkXAPP[:cs.KeyLen()], ivXAPP[:12], nil
 }
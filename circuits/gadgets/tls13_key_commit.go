@@ -0,0 +1,133 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	gnarkHash "github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// traffic key commitment schemes shared by the session commit, session data
+// and deco proxy circuits
+type KeyCommitScheme int
+
+const (
+	// sha256(tk), deterministic
+	KeyCommitSha256 KeyCommitScheme = iota
+	// sha256(tk || salt)
+	KeyCommitSha256Salted
+	// mimc over the key bytes followed by the salt bytes
+	KeyCommitMimc
+)
+
+// length of the secret salt of the salted schemes in bytes
+const KeyCommitSaltLen = 32
+
+func ParseKeyCommitScheme(name string) (KeyCommitScheme, error) {
+	switch name {
+	case "sha256":
+		return KeyCommitSha256, nil
+	case "sha256-salted":
+		return KeyCommitSha256Salted, nil
+	case "mimc":
+		return KeyCommitMimc, nil
+	}
+	return KeyCommitSha256, errors.New("unsupported key commitment scheme " + name)
+}
+
+func (s KeyCommitScheme) String() string {
+	switch s {
+	case KeyCommitSha256Salted:
+		return "sha256-salted"
+	case KeyCommitMimc:
+		return "mimc"
+	}
+	return "sha256"
+}
+
+// KeyCommitment computes the 32 byte commitment to key, mimc outputs are big endian
+func KeyCommitment(api frontend.API, scheme KeyCommitScheme, key, salt []frontend.Variable) ([32]frontend.Variable, error) {
+
+	var commit [32]frontend.Variable
+	if scheme != KeyCommitSha256 && len(salt) != KeyCommitSaltLen {
+		return commit, errors.New("salted key commitments require a 32 byte salt")
+	}
+
+	switch scheme {
+	case KeyCommitSha256, KeyCommitSha256Salted:
+		sha := NewSHA256(api)
+		if scheme == KeyCommitSha256Salted {
+			sha.Write(append(append([]frontend.Variable{}, key...), salt...))
+		} else {
+			sha.Write(key)
+		}
+		return sha.Sum(), nil
+
+	case KeyCommitMimc:
+		h, err := mimc.NewMiMC(api)
+		if err != nil {
+			return commit, err
+		}
+		h.Write(key...)
+		h.Write(salt...)
+		bits := api.ToBinary(h.Sum(), api.Compiler().FieldBitLen())
+		for i := 0; i < 32; i++ {
+			byteBits := make([]frontend.Variable, 8)
+			for j := 0; j < 8; j++ {
+				byteBits[j] = 0
+				if 8*(31-i)+j < len(bits) {
+					byteBits[j] = bits[8*(31-i)+j]
+				}
+			}
+			commit[i] = api.FromBinary(byteBits...)
+		}
+		return commit, nil
+	}
+
+	return commit, errors.New("unsupported key commitment scheme")
+}
+
+// CommitKey computes the key commitment out of circuit
+func CommitKey(scheme KeyCommitScheme, key, salt []byte) ([]byte, error) {
+
+	if scheme != KeyCommitSha256 && len(salt) != KeyCommitSaltLen {
+		return nil, errors.New("salted key commitments require a 32 byte salt")
+	}
+
+	switch scheme {
+	case KeyCommitSha256:
+		h := sha256.Sum256(key)
+		return h[:], nil
+	case KeyCommitSha256Salted:
+		h := sha256.Sum256(append(append([]byte{}, key...), salt...))
+		return h[:], nil
+	case KeyCommitMimc:
+		h := gnarkHash.MIMC_BN254.New()
+		var element [32]byte
+		for _, b := range append(append([]byte{}, key...), salt...) {
+			element[31] = b
+			h.Write(element[:])
+		}
+		return h.Sum(nil), nil
+	}
+
+	return nil, errors.New("unsupported key commitment scheme")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// key commitment evaluation
+type keyCommitWrapper struct {
+	Key    []frontend.Variable
+	Salt   []frontend.Variable
+	Commit [32]frontend.Variable `gnark:",public"`
+	Scheme KeyCommitScheme
+}
+
+func (circuit *keyCommitWrapper) Define(api frontend.API) error {
+	commit, err := KeyCommitment(api, circuit.Scheme, circuit.Key, circuit.Salt)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(commit[i], circuit.Commit[i])
+	}
+	return nil
+}
+
+func TestKeyCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	key := recordKey
+	salt := mustHex("5c3e2b7ad9f1a0e4c86b07d2f3195ae1b4c0d82f6e7a3915c4b8e02d7f6a1b93")
+	otherSalt := mustHex("00000000000000000000000000000000000000000000000000000000000000ff")
+
+	for _, scheme := range []KeyCommitScheme{KeyCommitSha256, KeyCommitSha256Salted, KeyCommitMimc} {
+		s := salt
+		if scheme == KeyCommitSha256 {
+			s = nil
+		}
+		commit, err := CommitKey(scheme, key, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newCircuit := func() *keyCommitWrapper {
+			return &keyCommitWrapper{
+				Key:    make([]frontend.Variable, len(key)),
+				Salt:   make([]frontend.Variable, len(s)),
+				Scheme: scheme,
+			}
+		}
+		newAssignment := func(salt []byte) *keyCommitWrapper {
+			assignment := newCircuit()
+			for i := 0; i < len(key); i++ {
+				assignment.Key[i] = key[i]
+			}
+			for i := 0; i < len(salt); i++ {
+				assignment.Salt[i] = salt[i]
+			}
+			for i := 0; i < 32; i++ {
+				assignment.Commit[i] = commit[i]
+			}
+			return assignment
+		}
+
+		assert.SolvingSucceeded(newCircuit(), newAssignment(s), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// a different salt opens to a different commitment
+		if scheme != KeyCommitSha256 {
+			assert.SolvingFailed(newCircuit(), newAssignment(otherSalt), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+	}
+
+	// salted schemes reject missing salts
+	if _, err := CommitKey(KeyCommitMimc, key, nil); err == nil {
+		t.Fatal("expected missing salt error")
+	}
+}
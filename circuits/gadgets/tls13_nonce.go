@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// evaluate nonce
+type NonceWrapper struct {
+	Iv             [12]frontend.Variable
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	Nonce          [12]frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *NonceWrapper) Define(api frontend.API) error {
+
+	nonce := RecordNonce(api, circuit.Iv, circuit.SequenceNumber)
+
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(nonce[i], circuit.Nonce[i])
+	}
+
+	return nil
+}
+
+// gnark per-record nonce, write_iv xor 64 bit big endian sequence number left padded to 12 bytes
+func RecordNonce(api frontend.API, iv [12]frontend.Variable, sequenceNumber frontend.Variable) [12]frontend.Variable {
+
+	// little endian bits, sequence number must fit into 64 bits
+	seqBits := api.ToBinary(sequenceNumber, 64)
+
+	var nonce [12]frontend.Variable
+	for i := 0; i < 4; i++ {
+		nonce[i] = iv[i]
+	}
+	for i := 4; i < 12; i++ {
+		// byte i of the nonce holds bits of the sequence number from most significant byte to least
+		start := (11 - i) * 8
+		ivBits := api.ToBinary(iv[i], 8)
+		x := make([]frontend.Variable, 8)
+		for j := 0; j < 8; j++ {
+			x[j] = api.Xor(ivBits[j], seqBits[start+j])
+		}
+		nonce[i] = api.FromBinary(x...)
+	}
+
+	return nonce
+}
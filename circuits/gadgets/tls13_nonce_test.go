@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordNonce(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	iv := recordIv
+	var sequenceNumber uint64 = 0x0102030405060708
+
+	// nonce = iv xor (0^32 || seq)
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], sequenceNumber)
+	for i := 0; i < 12; i++ {
+		nonce[i] ^= iv[i]
+	}
+
+	assignment := NonceWrapper{SequenceNumber: sequenceNumber}
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = iv[i]
+		assignment.Nonce[i] = nonce[i]
+	}
+	assert.SolvingSucceeded(&NonceWrapper{}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// nonce of a different sequence number must fail
+	assignment.SequenceNumber = sequenceNumber + 1
+	assert.SolvingFailed(&NonceWrapper{}, &assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+func TestCounterOverflow(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	nonce := recordIv
+	plaintext := []byte(recordPlaintext)[:32]
+
+	block, err := aes.NewCipher(recordKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two blocks of keystream, the 32 bit counter wraps as in gcm inc32
+	encrypt := func(counter uint32) []byte {
+		ciphertext := make([]byte, len(plaintext))
+		counterBlock := make([]byte, 16)
+		copy(counterBlock, nonce)
+		for i := 0; i < len(plaintext); i += 16 {
+			binary.BigEndian.PutUint32(counterBlock[12:], counter)
+			keystream := make([]byte, 16)
+			block.Encrypt(keystream, counterBlock)
+			for j := 0; j < 16; j++ {
+				ciphertext[i+j] = plaintext[i+j] ^ keystream[j]
+			}
+			counter++
+		}
+		return ciphertext
+	}
+
+	newCircuit := func() *LookUpAES128Wrapper {
+		return &LookUpAES128Wrapper{
+			LookUpAESWrapper{
+				Key:        make([]frontend.Variable, 16),
+				Plaintext:  make([]frontend.Variable, 32),
+				Ciphertext: make([]frontend.Variable, 32),
+			},
+		}
+	}
+	newAssignment := func(chunkIndex uint32) *LookUpAES128Wrapper {
+		assignment := newCircuit()
+		assignment.ChunkIndex = chunkIndex
+		assignment.Key = toVariables(recordKey)
+		for i := 0; i < 12; i++ {
+			assignment.Nonce[i] = nonce[i]
+		}
+		assignment.Plaintext = toVariables(plaintext)
+		assignment.Ciphertext = toVariables(encrypt(chunkIndex))
+		return assignment
+	}
+
+	// the last block may use counter 2^32-1
+	assert.SolvingSucceeded(newCircuit(), newAssignment(math.MaxUint32-1), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// a correctly encrypted chunk whose second block wraps to counter 0, counter 2^32 has no 32 bit iv
+	assert.SolvingFailed(newCircuit(), newAssignment(math.MaxUint32), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
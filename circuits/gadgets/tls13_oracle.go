@@ -17,16 +17,31 @@ limitations under the License.
 package gadgets
 
 import (
+	"errors"
+
 	"github.com/consensys/gnark/frontend"
 )
 
 type Tls13OracleWrapper struct {
-	// kdc params
-	DHSin                  [64]frontend.Variable
-	IntermediateHashHSopad [32]frontend.Variable `gnark:",public"`
-	MSin                   [32]frontend.Variable `gnark:",public"`
-	SATSin                 [32]frontend.Variable `gnark:",public"`
-	TkSAPPin               [32]frontend.Variable `gnark:",public"`
+	CipherSuite CipherSuite
+	// kdc params, sized by cipher suite hash
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable `gnark:",public"`
+	MSin                   []frontend.Variable `gnark:",public"`
+	SATSin                 []frontend.Variable `gnark:",public"`
+	TkSAPPin               []frontend.Variable `gnark:",public"`
+	IvSAPPin               []frontend.Variable `gnark:",public"`
+	// optional key exchange replacing DHSin and IntermediateHashHSopad
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable `gnark:",public"`
+	// optional handshake transcript replacing the kdc params, the key
+	// schedule starts from SharedSecret or the key exchange
+	SharedSecret        []frontend.Variable
+	Transcript          []frontend.Variable
+	ServerHelloEnd      int
+	ServerFinishedStart int
+	TranscriptHash      []frontend.Variable `gnark:",public"`
 	// TkCommit               [32]frontend.Variable `gnark:",public"`
 	// authtag params
 	IvCounter [16]frontend.Variable `gnark:",public"`
@@ -36,6 +51,7 @@ type Tls13OracleWrapper struct {
 	// record params
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
 	CipherChunks   []frontend.Variable   `gnark:",public"`
 	ChunkIndex     frontend.Variable     `gnark:",public"`
 	Substring      []frontend.Variable   `gnark:",public"`
@@ -44,6 +60,16 @@ type Tls13OracleWrapper struct {
 	ValueStart     int                   `gnark:",public"`
 	ValueEnd       int                   `gnark:",public"`
 	Threshold      frontend.Variable     `gnark:",public"`
+	// optional signed decimal values scaled by 10^Scale
+	Decimal bool
+	Scale   int
+	// optional bounded comparison, one element result if public
+	Comparison Comparison
+	Bits       int
+	Result     []frontend.Variable `gnark:",public"`
+	// optional value commitment, one element each if set
+	Salt            []frontend.Variable
+	ValueCommitment []frontend.Variable `gnark:",public"`
 }
 
 // Define declares the circuit's constraints
@@ -53,14 +79,26 @@ func (circuit *Tls13OracleWrapper) Define(api frontend.API) error {
 	oracle := NewTls13Oracle(api)
 
 	// set data
+	oracle.SetCipherSuite(circuit.CipherSuite)
 	oracle.SetKdcParams(
 		circuit.IntermediateHashHSopad,
 		circuit.MSin,
 		circuit.SATSin,
 		circuit.TkSAPPin,
+		circuit.IvSAPPin,
 		// circuit.TkCommit,
 		circuit.DHSin,
 	)
+	oracle.SetKeyExchange(circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+	if len(circuit.Transcript) > 0 {
+		oracle.SetHandshakeParams(
+			circuit.SharedSecret,
+			circuit.Transcript,
+			circuit.ServerHelloEnd,
+			circuit.ServerFinishedStart,
+			circuit.TranscriptHash,
+		)
+	}
 
 	oracle.SetAuthtagParams(
 		circuit.IvCounter,
@@ -81,24 +119,51 @@ func (circuit *Tls13OracleWrapper) Define(api frontend.API) error {
 		circuit.ValueStart,
 		circuit.ValueEnd,
 	)
+	oracle.SetSequenceNumber(circuit.SequenceNumber)
+	if circuit.Decimal {
+		oracle.SetDecimal(circuit.Scale)
+	}
+	var result frontend.Variable
+	if len(circuit.Result) > 0 {
+		result = circuit.Result[0]
+	}
+	oracle.SetComparison(circuit.Comparison, circuit.Bits, result)
+	if len(circuit.ValueCommitment) > 0 || len(circuit.Salt) > 0 {
+		if len(circuit.ValueCommitment) != 1 || len(circuit.Salt) != 1 {
+			return errors.New("value commitment requires one salt and one commitment")
+		}
+		oracle.SetValueCommitment(circuit.Salt[0], circuit.ValueCommitment[0])
+	}
 
 	// verify commitment
-	oracle.Assert()
-
-	return nil
+	return oracle.Assert()
 }
 
 type Tls13Oracle struct {
-	api frontend.API
+	api         frontend.API
+	CipherSuite CipherSuite
 
 	// kdc params
-	DHSin                  [64]frontend.Variable
-	IntermediateHashHSopad [32]frontend.Variable // `gnark:",public"`
-	MSin                   [32]frontend.Variable // `gnark:",public"`
-	XATSin                 [32]frontend.Variable // `gnark:",public"`
-	TkXAPPin               [32]frontend.Variable // `gnark:",public"`
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable // `gnark:",public"`
+	MSin                   []frontend.Variable // `gnark:",public"`
+	XATSin                 []frontend.Variable // `gnark:",public"`
+	TkXAPPin               []frontend.Variable // `gnark:",public"`
+	IvXAPPin               []frontend.Variable // `gnark:",public"`
 	// TkCommit               [32]frontend.Variable // `gnark:",public"`
 
+	// key exchange params
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable // `gnark:",public"`
+
+	// handshake params
+	SharedSecret        []frontend.Variable
+	Transcript          []frontend.Variable
+	ServerHelloEnd      int
+	ServerFinishedStart int
+	TranscriptHash      []frontend.Variable // `gnark:",public"`
+
 	// authtag params
 	IvCounter [16]frontend.Variable // `gnark:",public"`
 	Zeros     [16]frontend.Variable // `gnark:",public"`
@@ -108,6 +173,7 @@ type Tls13Oracle struct {
 	// record params
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable // `gnark:",public"`
+	SequenceNumber frontend.Variable     // `gnark:",public"`
 	CipherChunks   []frontend.Variable   // `gnark:",public"`
 	ChunkIndex     frontend.Variable     // `gnark:",public"`
 	Substring      []frontend.Variable   // `gnark:",public"`
@@ -116,21 +182,52 @@ type Tls13Oracle struct {
 	ValueStart     int                   // `gnark:",public"`
 	ValueEnd       int                   // `gnark:",public"`
 	Threshold      frontend.Variable     // `gnark:",public"`
+	Decimal        bool
+	Scale          int
+	Comparison     Comparison
+	Bits           int
+	Result         frontend.Variable // `gnark:",public"`
+	Salt           frontend.Variable
+	Commitment     frontend.Variable // `gnark:",public"`
 }
 
 func NewTls13Oracle(api frontend.API) Tls13Oracle {
 	return Tls13Oracle{api: api}
 }
 
-func (circuit *Tls13Oracle) SetKdcParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin [32]frontend.Variable, DHSin [64]frontend.Variable) {
+// defaults to TLS_AES_128_GCM_SHA256
+func (circuit *Tls13Oracle) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
+func (circuit *Tls13Oracle) SetKdcParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin, IvXAPPin, DHSin []frontend.Variable) {
 	circuit.IntermediateHashHSopad = IntermediateHashHSopad
 	circuit.MSin = MSin
 	circuit.XATSin = XATSin
 	circuit.TkXAPPin = TkXAPPin
+	circuit.IvXAPPin = IvXAPPin
 	// circuit.TkCommit = TkCommit
 	circuit.DHSin = DHSin
 }
 
+// optional in-circuit key exchange, the kdc then starts from the shared secret
+func (circuit *Tls13Oracle) SetKeyExchange(kex KeyExchange, clientSecret, serverShare []frontend.Variable) {
+	circuit.KeyExchange = kex
+	circuit.ClientSecret = clientSecret
+	circuit.ServerShare = serverShare
+}
+
+// optional handshake transcript replacing the kdc params, traffic key and iv
+// derive from the verified server Finished. the shared secret is unused with
+// a key exchange
+func (circuit *Tls13Oracle) SetHandshakeParams(sharedSecret, transcript []frontend.Variable, serverHelloEnd, serverFinishedStart int, transcriptHash []frontend.Variable) {
+	circuit.SharedSecret = sharedSecret
+	circuit.Transcript = transcript
+	circuit.ServerHelloEnd = serverHelloEnd
+	circuit.ServerFinishedStart = serverFinishedStart
+	circuit.TranscriptHash = transcriptHash
+}
+
 func (circuit *Tls13Oracle) SetAuthtagParams(ivCounter, zeros, ecb1, ecb0 [16]frontend.Variable) {
 	circuit.IvCounter = ivCounter
 	circuit.Zeros = zeros
@@ -151,43 +248,121 @@ func (circuit *Tls13Oracle) SetRecordParams(iv [12]frontend.Variable, plainChunk
 	circuit.ValueEnd = valueEnd
 }
 
-// Define declares the circuit's constraints
-func (circuit *Tls13Oracle) Assert() {
+func (circuit *Tls13Oracle) SetSequenceNumber(sequenceNumber frontend.Variable) {
+	circuit.SequenceNumber = sequenceNumber
+}
 
-	// kdc verification
+// optional signed decimal values scaled by 10^scale, the threshold must use
+// the same scale
+func (circuit *Tls13Oracle) SetDecimal(scale int) {
+	circuit.Decimal = true
+	circuit.Scale = scale
+}
 
-	// derive key
-	tls13_kdc := NewTls13Kdc(circuit.api)
-	tls13_kdc.SetParams(
-		circuit.IntermediateHashHSopad,
-		circuit.MSin,
-		circuit.XATSin,
-		circuit.TkXAPPin,
-		circuit.DHSin,
-	)
-	tk := tls13_kdc.Derive()
+// optional comparison and public predicate result, kdc, authtag and
+// decryption checks still fail on invalid data
+func (circuit *Tls13Oracle) SetComparison(comparison Comparison, bits int, result frontend.Variable) {
+	circuit.Comparison = comparison
+	circuit.Bits = bits
+	circuit.Result = result
+}
+
+// optional commitment mimc(value || salt) to the extracted value
+func (circuit *Tls13Oracle) SetValueCommitment(salt, commitment frontend.Variable) {
+	circuit.Salt = salt
+	circuit.Commitment = commitment
+}
+
+// Define declares the circuit's constraints
+func (circuit *Tls13Oracle) Assert() error {
+
+	// in-circuit key exchange
+	sharedSecret := circuit.SharedSecret
+	if circuit.KeyExchange != KeyExchangeNone {
+		var err error
+		sharedSecret, err = ECDHE(circuit.api, circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+		if err != nil {
+			return err
+		}
+	}
+
+	// derive key and iv
+	var tk, iv []frontend.Variable
+	if len(circuit.Transcript) > 0 {
+
+		// handshake verification, anchored by the public transcript hash
+		if len(circuit.TranscriptHash) != 32 {
+			return errors.New("handshake mode requires a 32 byte transcript hash")
+		}
+		handshake := NewTls13Handshake(circuit.api)
+		handshake.SetCipherSuite(circuit.CipherSuite)
+		handshake.SetParams(sharedSecret, circuit.Transcript, circuit.ServerHelloEnd, circuit.ServerFinishedStart)
+		handshake.SetTranscriptHash(circuit.TranscriptHash)
+		var err error
+		tk, iv, err = handshake.Derive()
+		if err != nil {
+			return err
+		}
+	} else {
+
+		// kdc verification
+		tls13_kdc := NewTls13Kdc(circuit.api)
+		tls13_kdc.SetCipherSuite(circuit.CipherSuite)
+		tls13_kdc.SetParams(
+			circuit.IntermediateHashHSopad,
+			circuit.MSin,
+			circuit.XATSin,
+			circuit.TkXAPPin,
+			circuit.IvXAPPin,
+			circuit.DHSin,
+		)
+		if circuit.KeyExchange != KeyExchangeNone {
+			tls13_kdc.SetSharedSecret(sharedSecret)
+		}
+		var err error
+		tk, iv, err = tls13_kdc.Derive()
+		if err != nil {
+			return err
+		}
+	}
+
+	// record iv must match the derived iv
+	for i := 0; i < 12; i++ {
+		circuit.api.AssertIsEqual(circuit.Iv[i], iv[i])
+	}
+
+	// authtag counter block must start with the record nonce
+	var iv12 [12]frontend.Variable
+	copy(iv12[:], iv)
+	nonce := iv12
+	if circuit.SequenceNumber != nil {
+		nonce = RecordNonce(circuit.api, iv12, circuit.SequenceNumber)
+	}
+	for i := 0; i < 12; i++ {
+		circuit.api.AssertIsEqual(circuit.IvCounter[i], nonce[i])
+	}
 
 	// authtag verification
 
 	// init
 	tag := NewTls13AuthTag(circuit.api)
-
-	// type conversion
-	var tk16 [16]frontend.Variable
-	copy(tk16[:], tk)
-	tag.SetParams(tk16, circuit.IvCounter, circuit.Zeros, circuit.ECB1, circuit.ECB0)
+	tag.SetCipherSuite(circuit.CipherSuite)
+	tag.SetParams(tk, circuit.IvCounter, circuit.Zeros, circuit.ECB1, circuit.ECB0)
 
 	// verify tag
-	tag.Assert()
+	if err := tag.Assert(); err != nil {
+		return err
+	}
 
 	// policy-based data verification
 
 	// init
 	record := NewTls13Record(circuit.api)
+	record.SetCipherSuite(circuit.CipherSuite)
 
 	// insert data
 	record.SetParams(
-		tk16,
+		tk,
 		circuit.Iv,
 		circuit.PlainChunks,
 		circuit.CipherChunks,
@@ -199,7 +374,16 @@ func (circuit *Tls13Oracle) Assert() {
 		circuit.ValueStart,
 		circuit.ValueEnd,
 	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	if circuit.Decimal {
+		record.SetDecimal(circuit.Scale)
+	}
+	record.SetComparison(circuit.Comparison, circuit.Bits)
+	record.SetResult(circuit.Result)
+	if circuit.Commitment != nil {
+		record.SetValueCommitment(circuit.Salt, circuit.Commitment)
+	}
 
 	// verify
-	record.Assert()
+	return record.Assert()
 }
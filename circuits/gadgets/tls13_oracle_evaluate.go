@@ -18,6 +18,7 @@ package gadgets
 
 import (
 	"encoding/hex"
+	"errors"
 	"strings"
 	"time"
 
@@ -26,7 +27,25 @@ import (
 )
 
 // execution of circuit function of program
-func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, error) {
+func EvaluateOracle(backend string, compile bool, cipherSuite CipherSuite) (map[string]time.Duration, error) {
+
+	circuit, assignment, err := oracleCircuits(cipherSuite)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ProofWithBackend(backend, compile, circuit, assignment, ecc.BN254)
+
+	return data, err
+}
+
+// circuit definition and assignment of the oracle evaluation data
+func oracleCircuits(cipherSuite CipherSuite) (*Tls13OracleWrapper, *Tls13OracleWrapper, error) {
+
+	// the authtag gadget requires an aes gcm suite
+	if !cipherSuite.IsAESGCM() {
+		return nil, nil, errors.New("unsupported cipher suite " + cipherSuite.String())
+	}
 
 	// kdc params
 	intermediateHashHSopad := "5113c2d6533a74ea90392417f726dc79c180819ad8a55bd809a5b38a0858b12f"
@@ -34,6 +53,7 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	MSin := "9be88f33141755dcc1846795217f8cd632559771fbd75fb45033ae0e3adfeefa"
 	SATSin := "dae6d4b1df8df6e1ccb7d90463601475c70c4958ad98c2de07141f8baf77390b"
 	tkSAPPin := "2feeba2461c64d98bd39a71ee1f20e59e7d85b3d99ad6a0e4fc8e29c3d9e8e0a"
+	ivSAPPin := "6bbfa94c0a44c3f416918b26793f913b2b14d54ee306cabde3505516dfb7dd27"
 	// authtag params
 	iv := "a54613bf2801a84ce693d0a0"
 	zeros := "00000000000000000000000000000000"
@@ -42,13 +62,32 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	// record params
 	chipherChunks := "419a031754a4897806533c6020e9130f6088747b9f9a1e1eba4cb0518a6d5692"
 	plainChunks := "302c353631204575726f227d2c227072696365223a2233383030322e32222c22"
+	sequenceNumber := 0
 	chunkIndex := 32
 	substring := "\"price\""
 	substringStart := 13
 	substringEnd := 20
 	valueStart := 23
-	valueEnd := 28
-	threshold := 38003
+	valueEnd := 30
+	scale := 2
+	threshold, err := DecimalToScaled("38002.15", scale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// TLS_AES_256_GCM_SHA384 data
+	if cipherSuite == TLS_AES_256_GCM_SHA384 {
+		intermediateHashHSopad = "c9dc4a59050490de9ac41a55e524b0aef8d4911eb9bd63f8ee899e08accc492ba2407aa2b31dee1160bd4b8ec40e3cc58032559e5f199bcb47f074af2906e732"
+		dHSin = "e200c89774b2af02021aaf6af98bc38eabd4602505ee4b87656358a351ed460640c462afd98ae1dcdea1544bbbe5b64d"
+		MSin = "470dfdef3dbbde75776c9f94bfa6ab77917a77a83da4cdd42709c67849cc7c385b40074e6de1ad269a95c5b870c08812"
+		SATSin = "9e6e0dc72f3ce34b5e2e4639da6fb04ebad5bf72b4ef122362fd55b53a6057935c07bc96ebec417c6b875ac2405e9449"
+		tkSAPPin = "33b9958f24f08e6e86139876f66fcf7e6ebca54a145ebc6323aae1d7cc4ab3a6bd76a10684ec3f2ddbf2a9b5a6f1c806"
+		ivSAPPin = "df8162a6c0bb8cff001ba912355f759b970e05d902f338955a1e84d460f4391618baad5e8d759fe8dc1efb81fb685c25"
+		iv = "d4d2d46ec5204db1f05be5ba"
+		ecb1 = "e5bc412e204a4c3f6abfe1c787277dc0"
+		ecb0 = "4f649d0edfd5829cd89ae455cfb156b3"
+		chipherChunks = "193397e0881072d9c854862b865146ae049ceef374fe89405d4a9411480e5d1f"
+	}
 
 	// add counter to iv bytes
 	var sb strings.Builder
@@ -72,6 +111,8 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	SATSinByteLen := len(byteSlice)
 	byteSlice, _ = hex.DecodeString(tkSAPPin)
 	tkSAPPinByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(ivSAPPin)
+	ivSAPPinByteLen := len(byteSlice)
 	// authtag to bytes
 	byteSlice, _ = hex.DecodeString(ivCounter)
 	ivCounterByteLen := len(byteSlice)
@@ -90,13 +131,16 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	plainChunksByteLen := len(byteSlice)
 	substringByteLen := len(substring)
 
-	// add padding out of circuit
-	pad := PadSha256(96)
-	dHSinPadded := make([]byte, 32+len(pad))
+	// add padding out of circuit, inner hash follows one block of the hash function
+	pad := PadSha256(uint64(cipherSuite.BlockLen() + dHSinByteLen))
+	if cipherSuite == TLS_AES_256_GCM_SHA384 {
+		pad = PadSha384(uint64(cipherSuite.BlockLen() + dHSinByteLen))
+	}
+	dHSinPadded := make([]byte, dHSinByteLen+len(pad))
 	copy(dHSinPadded, dHSSlice)
-	copy(dHSinPadded[32:], pad)
+	copy(dHSinPadded[dHSinByteLen:], pad)
 	newdHSin := hex.EncodeToString(dHSinPadded)
-	dHSinByteLen += 32
+	dHSinByteLen += len(pad)
 
 	// witness definition kdc
 	intermediateHashHSopadAssign := StrToIntSlice(intermediateHashHSopad, true)
@@ -104,6 +148,7 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	MSinAssign := StrToIntSlice(MSin, true)
 	SATSinAssign := StrToIntSlice(SATSin, true)
 	tkSAPPinAssign := StrToIntSlice(tkSAPPin, true)
+	ivSAPPinAssign := StrToIntSlice(ivSAPPin, true)
 	// witness definition authtag
 	ivCounterAssign := StrToIntSlice(ivCounter, true)
 	zerosAssign := StrToIntSlice(zeros, true)
@@ -118,11 +163,12 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	// witness values preparation
 	assignment := Tls13OracleWrapper{
 		// kdc params
-		IntermediateHashHSopad: [32]frontend.Variable{},
-		DHSin:                  [64]frontend.Variable{},
-		MSin:                   [32]frontend.Variable{},
-		SATSin:                 [32]frontend.Variable{},
-		TkSAPPin:               [32]frontend.Variable{},
+		IntermediateHashHSopad: make([]frontend.Variable, intermediateHashHSopadByteLen),
+		DHSin:                  make([]frontend.Variable, dHSinByteLen),
+		MSin:                   make([]frontend.Variable, MSinByteLen),
+		SATSin:                 make([]frontend.Variable, SATSinByteLen),
+		TkSAPPin:               make([]frontend.Variable, tkSAPPinByteLen),
+		IvSAPPin:               make([]frontend.Variable, ivSAPPinByteLen),
 		// authtag params
 		IvCounter: [16]frontend.Variable{},
 		Zeros:     [16]frontend.Variable{},
@@ -132,6 +178,7 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
 		Iv:             [12]frontend.Variable{},
 		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+		SequenceNumber: sequenceNumber,
 		ChunkIndex:     chunkIndex,
 		Substring:      make([]frontend.Variable, substringByteLen),
 		SubstringStart: substringStart,
@@ -139,6 +186,8 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 		ValueStart:     valueStart,
 		ValueEnd:       valueEnd,
 		Threshold:      threshold,
+		Decimal:        true,
+		Scale:          scale,
 	}
 
 	// kdc assign
@@ -157,6 +206,9 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 	for i := 0; i < tkSAPPinByteLen; i++ {
 		assignment.TkSAPPin[i] = tkSAPPinAssign[i]
 	}
+	for i := 0; i < ivSAPPinByteLen; i++ {
+		assignment.IvSAPPin[i] = ivSAPPinAssign[i]
+	}
 	// authtag assign
 	for i := 0; i < ivCounterByteLen; i++ {
 		assignment.IvCounter[i] = ivCounterAssign[i]
@@ -186,16 +238,23 @@ func EvaluateOracle(backend string, compile bool) (map[string]time.Duration, err
 
 	// var circuit kdcServerKey
 	circuit := Tls13OracleWrapper{
-		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
-		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
-		Substring:      make([]frontend.Variable, substringByteLen),
-		SubstringStart: substringStart,
-		SubstringEnd:   substringEnd,
-		ValueStart:     valueStart,
-		ValueEnd:       valueEnd,
+		CipherSuite:            cipherSuite,
+		IntermediateHashHSopad: make([]frontend.Variable, intermediateHashHSopadByteLen),
+		DHSin:                  make([]frontend.Variable, dHSinByteLen),
+		MSin:                   make([]frontend.Variable, MSinByteLen),
+		SATSin:                 make([]frontend.Variable, SATSinByteLen),
+		TkSAPPin:               make([]frontend.Variable, tkSAPPinByteLen),
+		IvSAPPin:               make([]frontend.Variable, ivSAPPinByteLen),
+		PlainChunks:            make([]frontend.Variable, plainChunksByteLen),
+		CipherChunks:           make([]frontend.Variable, chipherChunksByteLen),
+		Substring:              make([]frontend.Variable, substringByteLen),
+		SubstringStart:         substringStart,
+		SubstringEnd:           substringEnd,
+		ValueStart:             valueStart,
+		ValueEnd:               valueEnd,
+		Decimal:                true,
+		Scale:                  scale,
 	}
 
-	data, err := ProofWithBackend(backend, compile, &circuit, &assignment, ecc.BN254)
-
-	return data, err
+	return &circuit, &assignment, nil
 }
@@ -0,0 +1,132 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestOracleResult(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// price 38002.2 against threshold 38002.15
+	for _, c := range []struct {
+		comparison Comparison
+		result     int
+	}{
+		{GT, 1},
+		{LTE, 0},
+	} {
+		circuit, assignment, err := oracleCircuits(TLS_AES_128_GCM_SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		circuit.Comparison = c.comparison
+		circuit.Bits = 32
+		circuit.Result = make([]frontend.Variable, 1)
+		assignment.Comparison = c.comparison
+		assignment.Bits = 32
+		assignment.Result = []frontend.Variable{c.result}
+
+		assert.SolvingSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// the opposite result must fail
+		assignment.Result[0] = 1 - c.result
+		assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestOracleHandshake(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	sharedSecret := mustHex("8bd4054fb55b9d63fdfbacf9f04b9f0d35e6d63f537563efd46272900f89492d")
+	transcript, serverHelloEnd, serverFinishedStart, tk, iv := handshakeTranscript(sharedSecret)
+	transcriptHash := sha256.Sum256(transcript)
+
+	// first record under the derived traffic key
+	plaintext := recordPlaintext
+	substring := `"price"`
+	substringStart := strings.Index(plaintext, substring)
+	valueStart := strings.Index(plaintext, "38002")
+	block, err := aes.NewCipher(tk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := aesgcm.Seal(nil, iv, []byte(plaintext), nil)[:len(plaintext)]
+
+	// authtag blocks of the traffic key
+	ivCounter := append(append([]byte{}, iv...), 0, 0, 0, 1)
+	ecb0 := make([]byte, 16)
+	ecb1 := make([]byte, 16)
+	block.Encrypt(ecb0, make([]byte, 16))
+	block.Encrypt(ecb1, ivCounter)
+
+	newCircuit := func() *Tls13OracleWrapper {
+		return &Tls13OracleWrapper{
+			SharedSecret:        make([]frontend.Variable, len(sharedSecret)),
+			Transcript:          make([]frontend.Variable, len(transcript)),
+			ServerHelloEnd:      serverHelloEnd,
+			ServerFinishedStart: serverFinishedStart,
+			TranscriptHash:      make([]frontend.Variable, 32),
+			PlainChunks:         make([]frontend.Variable, len(plaintext)),
+			CipherChunks:        make([]frontend.Variable, len(plaintext)),
+			Substring:           make([]frontend.Variable, len(substring)),
+			SubstringStart:      substringStart,
+			SubstringEnd:        substringStart + len(substring),
+			ValueStart:          valueStart,
+			ValueEnd:            valueStart + 5,
+		}
+	}
+
+	assignment := newCircuit()
+	assignment.SharedSecret = toVariables(sharedSecret)
+	assignment.Transcript = toVariables(transcript)
+	assignment.TranscriptHash = toVariables(transcriptHash[:])
+	assignment.PlainChunks = toVariables([]byte(plaintext))
+	assignment.CipherChunks = toVariables(ciphertext)
+	assignment.Substring = toVariables([]byte(substring))
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 2
+	assignment.Threshold = 38000
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = iv[i]
+	}
+	for i := 0; i < 16; i++ {
+		assignment.IvCounter[i] = ivCounter[i]
+		assignment.Zeros[i] = 0
+		assignment.ECB0[i] = ecb0[i]
+		assignment.ECB1[i] = ecb1[i]
+	}
+	assert.SolvingSucceeded(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// the key schedule is anchored by the public transcript hash
+	assignment.TranscriptHash[0] = transcriptHash[0] ^ 1
+	assert.SolvingFailed(newCircuit(), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
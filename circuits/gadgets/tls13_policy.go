@@ -0,0 +1,174 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// node types of policy trees
+type PolicyOp int
+
+const (
+	PolicyOpCompare PolicyOp = iota
+	PolicyOpMatch
+	PolicyOpAnd
+	PolicyOpOr
+	PolicyOpNot
+)
+
+// policy tree, leaves compare an extracted field against an operand and
+// inner nodes combine the results of their children. the tree is fixed at
+// circuit build time, operands are indices into thresholds or texts
+type PolicyNode struct {
+	Op         PolicyOp
+	Children   []PolicyNode
+	Field      int
+	Comparison Comparison
+	Operand    int
+}
+
+// numeric leaf, the field is parsed with StringToInt
+func PolicyCompare(field int, comparison Comparison, threshold int) PolicyNode {
+	return PolicyNode{Op: PolicyOpCompare, Field: field, Comparison: comparison, Operand: threshold}
+}
+
+// string leaf, the field must equal the text
+func PolicyMatch(field int, text int) PolicyNode {
+	return PolicyNode{Op: PolicyOpMatch, Field: field, Operand: text}
+}
+
+func PolicyAnd(children ...PolicyNode) PolicyNode {
+	return PolicyNode{Op: PolicyOpAnd, Children: children}
+}
+
+func PolicyOr(children ...PolicyNode) PolicyNode {
+	return PolicyNode{Op: PolicyOpOr, Children: children}
+}
+
+func PolicyNot(child PolicyNode) PolicyNode {
+	return PolicyNode{Op: PolicyOpNot, Children: []PolicyNode{child}}
+}
+
+// value offsets of a policy field in the plaintext, the key must directly
+// precede the value, e.g. `"price":"`
+type PolicyField struct {
+	Key   string
+	Start int
+	End   int
+}
+
+// policy evaluation over extracted fields
+type Policy struct {
+	api        frontend.API
+	fields     [][]frontend.Variable
+	thresholds []frontend.Variable
+	texts      [][]frontend.Variable
+	bits       int
+	integers   map[int]frontend.Variable
+}
+
+// numeric comparisons are bounded by bits, see AssertComparison
+func NewPolicy(api frontend.API, fields [][]frontend.Variable, thresholds []frontend.Variable, texts [][]frontend.Variable, bits int) Policy {
+	return Policy{
+		api:        api,
+		fields:     fields,
+		thresholds: thresholds,
+		texts:      texts,
+		bits:       bits,
+		integers:   map[int]frontend.Variable{},
+	}
+}
+
+// Evaluate returns 1 if the policy holds and 0 otherwise
+func (p *Policy) Evaluate(node PolicyNode) (frontend.Variable, error) {
+
+	api := p.api
+
+	switch node.Op {
+	case PolicyOpCompare:
+		if node.Field < 0 || node.Field >= len(p.fields) || node.Operand < 0 || node.Operand >= len(p.thresholds) {
+			return nil, errors.New("invalid policy comparison on field " + strconv.Itoa(node.Field))
+		}
+		if p.bits == 0 {
+			return nil, errors.New("policy comparisons require a bit width")
+		}
+		return IsComparison(api, node.Comparison, p.integer(node.Field), p.thresholds[node.Operand], p.bits), nil
+
+	case PolicyOpMatch:
+		if node.Field < 0 || node.Field >= len(p.fields) || node.Operand < 0 || node.Operand >= len(p.texts) {
+			return nil, errors.New("invalid policy match on field " + strconv.Itoa(node.Field))
+		}
+		return IsSubstringMatch(api, p.texts[node.Operand], p.fields[node.Field]), nil
+
+	case PolicyOpAnd, PolicyOpOr:
+		if len(node.Children) == 0 {
+			return nil, errors.New("policy and/or without children")
+		}
+		result, err := p.Evaluate(node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range node.Children[1:] {
+			r, err := p.Evaluate(child)
+			if err != nil {
+				return nil, err
+			}
+			if node.Op == PolicyOpAnd {
+				result = api.And(result, r)
+			} else {
+				result = api.Or(result, r)
+			}
+		}
+		return result, nil
+
+	case PolicyOpNot:
+		if len(node.Children) != 1 {
+			return nil, errors.New("policy not requires one child")
+		}
+		r, err := p.Evaluate(node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		return api.Sub(1, r), nil
+	}
+
+	return nil, errors.New("unsupported policy node")
+}
+
+// Assert checks that the policy holds
+func (p *Policy) Assert(node PolicyNode) error {
+	result, err := p.Evaluate(node)
+	if err != nil {
+		return err
+	}
+	p.api.AssertIsEqual(result, 1)
+	return nil
+}
+
+// integer of a field, converted once per field
+func (p *Policy) integer(field int) frontend.Variable {
+	if v, ok := p.integers[field]; ok {
+		return v
+	}
+	v := StringToInt(p.api, p.fields[field])
+	p.integers[field] = v
+	return v
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecordPolicy(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// country == DE AND (balance > 1000 OR tier == gold)
+	policy := PolicyAnd(
+		PolicyMatch(0, 0),
+		PolicyOr(
+			PolicyCompare(1, GT, 0),
+			PolicyMatch(2, 1),
+		),
+	)
+	format := `{"country":"%s","balance":"%s","tier":"%s"}`
+	fields := []PolicyField{{`"country":"`, 12, 14}, {`"balance":"`, 27, 31}, {`"tier":"`, 41, 45}}
+	texts := []string{"DE", "gold"}
+
+	records := []struct {
+		country string
+		balance string
+		tier    string
+		result  int
+	}{
+		{"DE", "1500", "free", 1},
+		{"DE", "0500", "gold", 1},
+		{"DE", "0500", "free", 0},
+		{"FR", "1500", "gold", 0},
+	}
+
+	newCircuit := func(plainLen int) *RecordPolicyWrapper {
+		circuit := &RecordPolicyWrapper{
+			PlainChunks:  make([]frontend.Variable, plainLen),
+			CipherChunks: make([]frontend.Variable, plainLen),
+			Thresholds:   make([]frontend.Variable, 1),
+			Texts:        make([][]frontend.Variable, len(texts)),
+			Fields:       fields,
+			Policy:       policy,
+			Bits:         32,
+		}
+		for i := range texts {
+			circuit.Texts[i] = make([]frontend.Variable, len(texts[i]))
+		}
+		return circuit
+	}
+
+	for _, record := range records {
+		plaintext := fmt.Sprintf(format, record.country, record.balance, record.tier)
+
+		assignment := newCircuit(len(plaintext))
+		assignment.SequenceNumber = 0
+		assignment.ChunkIndex = 2
+		assignment.Thresholds[0] = 1000
+		assignment.Result = record.result
+		assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, plaintext)
+		for i := range texts {
+			assignment.Texts[i] = toVariables([]byte(texts[i]))
+		}
+
+		assert.SolvingSucceeded(newCircuit(len(plaintext)), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// the opposite result must fail
+		assignment.Result = 1 - record.result
+		assert.SolvingFailed(newCircuit(len(plaintext)), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// fields must follow their keys
+		assignment.Result = record.result
+		circuit := newCircuit(len(plaintext))
+		circuit.Fields = []PolicyField{fields[0], {`"tier":"`, 27, 31}, fields[2]}
+		assert.SolvingFailed(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
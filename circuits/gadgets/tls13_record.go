@@ -17,6 +17,9 @@ limitations under the License.
 package gadgets
 
 import (
+	"errors"
+	"math/big"
+
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -25,6 +28,7 @@ type RecordWrapper struct {
 	Key            [16]frontend.Variable
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
 	CipherChunks   []frontend.Variable   `gnark:",public"`
 	ChunkIndex     frontend.Variable     `gnark:",public"`
 	Substring      []frontend.Variable   `gnark:",public"`
@@ -33,6 +37,8 @@ type RecordWrapper struct {
 	ValueStart     int                   `gnark:",public"`
 	ValueEnd       int                   `gnark:",public"`
 	Threshold      frontend.Variable     `gnark:",public"`
+	Comparison     Comparison
+	Bits           int
 }
 
 func (circuit *RecordWrapper) Define(api frontend.API) error {
@@ -41,7 +47,238 @@ func (circuit *RecordWrapper) Define(api frontend.API) error {
 
 	// insert data
 	record.SetParams(
-		circuit.Key,
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetComparison(circuit.Comparison, circuit.Bits)
+
+	// verify
+	record.Assert()
+
+	return nil
+}
+
+// evaluate record with authentication tag over the full record
+type RecordTagWrapper struct {
+	RecordWrapper
+	Aad [5]frontend.Variable  `gnark:",public"`
+	Tag [16]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordTagWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetTagParams(circuit.Aad[:], circuit.Tag)
+	record.SetComparison(circuit.Comparison, circuit.Bits)
+
+	// verify
+	record.Assert()
+
+	return nil
+}
+
+// evaluate record with the predicate result as public output, decryption
+// must hold while the comparison may be true or false
+type RecordResultWrapper struct {
+	RecordWrapper
+	Result frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordResultWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetComparison(circuit.Comparison, circuit.Bits)
+	record.SetResult(circuit.Result)
+
+	// verify
+	return record.Assert()
+}
+
+// evaluate record against a policy tree over several fields, the
+// policy result is public
+type RecordPolicyWrapper struct {
+	Key            [16]frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	Thresholds     []frontend.Variable   `gnark:",public"`
+	Texts          [][]frontend.Variable `gnark:",public"`
+	Result         frontend.Variable     `gnark:",public"`
+	Fields         []PolicyField         `gnark:"-"`
+	Policy         PolicyNode            `gnark:"-"`
+	Bits           int
+}
+
+func (circuit *RecordPolicyWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data, substring, threshold and int offsets are unused
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		nil,
+		circuit.ChunkIndex,
+		0,
+		0, 0, 0, 0,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetPolicy(circuit.Policy, circuit.Fields, circuit.Thresholds, circuit.Texts)
+	record.SetComparison(GTE, circuit.Bits)
+	record.SetResult(circuit.Result)
+
+	// verify
+	return record.Assert()
+}
+
+// evaluate record with public redacted plaintext, bytes with mask bit
+// set are revealed and all others are zero
+type RecordDisclosureWrapper struct {
+	RecordWrapper
+	Mask     []frontend.Variable `gnark:",public"`
+	Revealed []frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordDisclosureWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetComparison(circuit.Comparison, circuit.Bits)
+	record.SetDisclosure(circuit.Mask, circuit.Revealed)
+
+	// verify
+	return record.Assert()
+}
+
+// evaluate record with a public commitment to the value instead of a threshold comparison
+type RecordCommitmentWrapper struct {
+	Key             [16]frontend.Variable
+	PlainChunks     []frontend.Variable
+	Iv              [12]frontend.Variable `gnark:",public"`
+	SequenceNumber  frontend.Variable     `gnark:",public"`
+	CipherChunks    []frontend.Variable   `gnark:",public"`
+	ChunkIndex      frontend.Variable     `gnark:",public"`
+	Substring       []frontend.Variable   `gnark:",public"`
+	SubstringStart  int                   `gnark:",public"`
+	SubstringEnd    int                   `gnark:",public"`
+	ValueStart      int                   `gnark:",public"`
+	ValueEnd        int                   `gnark:",public"`
+	Salt            frontend.Variable
+	ValueCommitment frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordCommitmentWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data, without threshold
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		nil,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetValueCommitment(circuit.Salt, circuit.ValueCommitment)
+
+	// verify
+	return record.Assert()
+}
+
+// evaluate chacha20-poly1305 record with tag over the full record
+type RecordChaChaWrapper struct {
+	Key            [32]frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	Substring      []frontend.Variable   `gnark:",public"`
+	SubstringStart int                   `gnark:",public"`
+	SubstringEnd   int                   `gnark:",public"`
+	ValueStart     int                   `gnark:",public"`
+	ValueEnd       int                   `gnark:",public"`
+	Threshold      frontend.Variable     `gnark:",public"`
+	Aad            [5]frontend.Variable  `gnark:",public"`
+	Tag            [16]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordChaChaWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+	record.SetCipherSuite(TLS_CHACHA20_POLY1305_SHA256)
+
+	// insert data
+	record.SetParams(
+		circuit.Key[:],
 		circuit.Iv,
 		circuit.PlainChunks,
 		circuit.CipherChunks,
@@ -53,6 +290,8 @@ func (circuit *RecordWrapper) Define(api frontend.API) error {
 		circuit.ValueStart,
 		circuit.ValueEnd,
 	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetTagParams(circuit.Aad[:], circuit.Tag)
 
 	// verify
 	record.Assert()
@@ -60,11 +299,149 @@ func (circuit *RecordWrapper) Define(api frontend.API) error {
 	return nil
 }
 
+// evaluate record with witness-dependent substring and value offsets
+type RecordOffsetsWrapper struct {
+	Key            [16]frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	Substring      []frontend.Variable   `gnark:",public"`
+	SubstringStart frontend.Variable     `gnark:",public"`
+	SubstringEnd   frontend.Variable     `gnark:",public"`
+	ValueStart     frontend.Variable
+	ValueEnd       frontend.Variable
+	MaxValueLen    int
+	Threshold      frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordOffsetsWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data, int offsets are unused
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		circuit.Substring,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		0, 0, 0, 0,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetOffsets(RecordOffsets{
+		SubstringStart: circuit.SubstringStart,
+		SubstringEnd:   circuit.SubstringEnd,
+		ValueStart:     circuit.ValueStart,
+		ValueEnd:       circuit.ValueEnd,
+		MaxValueLen:    circuit.MaxValueLen,
+	})
+
+	// verify
+	record.Assert()
+
+	return nil
+}
+
+// evaluate record with the value located by a json key path
+type RecordJsonWrapper struct {
+	Key            [16]frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	KeyPath        []string
+	KeyStarts      []frontend.Variable
+	ValueStart     frontend.Variable
+	ValueEnd       frontend.Variable
+	MaxValueLen    int
+	Threshold      frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordJsonWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data, substring and int offsets are unused
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		nil,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		0, 0, 0, 0,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetOffsets(RecordOffsets{
+		ValueStart:  circuit.ValueStart,
+		ValueEnd:    circuit.ValueEnd,
+		MaxValueLen: circuit.MaxValueLen,
+	})
+	record.SetKeyPath(circuit.KeyPath, circuit.KeyStarts)
+
+	// verify
+	record.Assert()
+
+	return nil
+}
+
+// evaluate record with the value captured by a regular expression
+type RecordRegexWrapper struct {
+	Key            [16]frontend.Variable
+	PlainChunks    []frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
+	CipherChunks   []frontend.Variable   `gnark:",public"`
+	ChunkIndex     frontend.Variable     `gnark:",public"`
+	Pattern        string
+	MatchStart     frontend.Variable
+	MatchEnd       frontend.Variable
+	ValueStart     frontend.Variable
+	ValueEnd       frontend.Variable
+	MaxValueLen    int
+	Threshold      frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RecordRegexWrapper) Define(api frontend.API) error {
+
+	record := NewTls13Record(api)
+
+	// insert data, substring and int offsets are unused
+	record.SetParams(
+		circuit.Key[:],
+		circuit.Iv,
+		circuit.PlainChunks,
+		circuit.CipherChunks,
+		nil,
+		circuit.ChunkIndex,
+		circuit.Threshold,
+		0, 0, 0, 0,
+	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	record.SetOffsets(RecordOffsets{
+		ValueStart:  circuit.ValueStart,
+		ValueEnd:    circuit.ValueEnd,
+		MaxValueLen: circuit.MaxValueLen,
+	})
+	record.SetRegex(circuit.Pattern, circuit.MatchStart, circuit.MatchEnd)
+
+	// verify
+	return record.Assert()
+}
+
 type Tls13Record struct {
 	api            frontend.API
-	Key            [16]frontend.Variable
+	CipherSuite    CipherSuite
+	Key            []frontend.Variable
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable // `gnark:",public"`
+	SequenceNumber frontend.Variable     // `gnark:",public"`
 	CipherChunks   []frontend.Variable   // `gnark:",public"`
 	ChunkIndex     frontend.Variable     // `gnark:",public"`
 	Substring      []frontend.Variable   // `gnark:",public"`
@@ -73,13 +450,34 @@ type Tls13Record struct {
 	ValueStart     int                   // `gnark:",public"`
 	ValueEnd       int                   // `gnark:",public"`
 	Threshold      frontend.Variable     // `gnark:",public"`
+	Aad            []frontend.Variable   // `gnark:",public"`
+	Tag            [16]frontend.Variable // `gnark:",public"`
+	Offsets        *RecordOffsets
+	KeyPath        []string
+	KeyStarts      []frontend.Variable
+	Pattern        string
+	MatchStart     frontend.Variable
+	MatchEnd       frontend.Variable
+	Decimal        bool
+	Scale          int
+	Comparison     Comparison
+	Bits           int
+	Result         frontend.Variable
+	Policy         *PolicyNode
+	PolicyFields   []PolicyField
+	Thresholds     []frontend.Variable
+	Texts          [][]frontend.Variable
+	Mask           []frontend.Variable
+	Revealed       []frontend.Variable
+	Salt           frontend.Variable
+	Commitment     frontend.Variable
 }
 
 func NewTls13Record(api frontend.API) Tls13Record {
 	return Tls13Record{api: api}
 }
 
-func (circuit *Tls13Record) SetParams(key [16]frontend.Variable, iv [12]frontend.Variable, plainChunks, cipherChunks, substring []frontend.Variable, chunkIndex, threshold frontend.Variable, substringStart, substringEnd, valueStart, valueEnd int) {
+func (circuit *Tls13Record) SetParams(key []frontend.Variable, iv [12]frontend.Variable, plainChunks, cipherChunks, substring []frontend.Variable, chunkIndex, threshold frontend.Variable, substringStart, substringEnd, valueStart, valueEnd int) {
 	circuit.Key = key
 	circuit.PlainChunks = plainChunks
 	circuit.Iv = iv
@@ -93,30 +491,287 @@ func (circuit *Tls13Record) SetParams(key [16]frontend.Variable, iv [12]frontend
 	circuit.ValueEnd = valueEnd
 }
 
+// defaults to TLS_AES_128_GCM_SHA256, key length must match the cipher suite
+func (circuit *Tls13Record) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
+// optional sequence number, the record nonce is computed from iv and sequence number if set
+func (circuit *Tls13Record) SetSequenceNumber(sequenceNumber frontend.Variable) {
+	circuit.SequenceNumber = sequenceNumber
+}
+
+// optional tag verification, requires the full record starting at counter 2
+// (block counter 1 for chacha20-poly1305) and the record header as additional
+// authenticated data
+func (circuit *Tls13Record) SetTagParams(aad []frontend.Variable, tag [16]frontend.Variable) {
+	circuit.Aad = aad
+	circuit.Tag = tag
+}
+
+// optional witness-dependent offsets, replaces the int offsets of SetParams
+func (circuit *Tls13Record) SetOffsets(offsets RecordOffsets) {
+	circuit.Offsets = &offsets
+}
+
+// optional json key path, replaces the substring match. keyStarts are the
+// positions of the opening quotes of the keys in path
+func (circuit *Tls13Record) SetKeyPath(path []string, keyStarts []frontend.Variable) {
+	circuit.KeyPath = path
+	circuit.KeyStarts = keyStarts
+}
+
+// optional regular expression, replaces the substring match. the pattern must match
+// plaintext[matchStart:matchEnd] and its capture group the value offsets
+func (circuit *Tls13Record) SetRegex(pattern string, matchStart, matchEnd frontend.Variable) {
+	circuit.Pattern = pattern
+	circuit.MatchStart = matchStart
+	circuit.MatchEnd = matchEnd
+}
+
+// optional signed decimal value scaled by 10^scale, the threshold must use the same scale
+func (circuit *Tls13Record) SetDecimal(scale int) {
+	circuit.Decimal = true
+	circuit.Scale = scale
+}
+
+// optional comparison of value against threshold for values below 2^bits, signed
+// decimals must lie in [-2^(bits-1), 2^(bits-1)). zero bits keep the unbounded
+// value >= threshold check
+func (circuit *Tls13Record) SetComparison(comparison Comparison, bits int) {
+	circuit.Comparison = comparison
+	circuit.Bits = bits
+}
+
+// optional predicate result, the comparison is evaluated to result instead
+// of being asserted. requires a comparison bit width
+func (circuit *Tls13Record) SetResult(result frontend.Variable) {
+	circuit.Result = result
+}
+
+// optional policy tree over the fields, replaces the substring match and the
+// threshold comparison. numeric leaves use the comparison bit width and the
+// policy is evaluated to the predicate result if set
+func (circuit *Tls13Record) SetPolicy(policy PolicyNode, fields []PolicyField, thresholds []frontend.Variable, texts [][]frontend.Variable) {
+	circuit.Policy = &policy
+	circuit.PolicyFields = fields
+	circuit.Thresholds = thresholds
+	circuit.Texts = texts
+}
+
+// optional selective disclosure, revealed must equal the verified plaintext
+// where mask is 1 and zero where mask is 0
+func (circuit *Tls13Record) SetDisclosure(mask, revealed []frontend.Variable) {
+	circuit.Mask = mask
+	circuit.Revealed = revealed
+}
+
+// optional commitment mimc(value || salt) to the extracted value, the
+// threshold comparison is skipped if the threshold is nil
+func (circuit *Tls13Record) SetValueCommitment(salt, commitment frontend.Variable) {
+	circuit.Salt = salt
+	circuit.Commitment = commitment
+}
+
 // Define declares the circuit's constraints
 func (circuit *Tls13Record) Assert() error {
 
-	// aes circuit
-	//aes := NewAES128(circuit.api) // groth16
-	aes := NewLookUpAES128(circuit.api) // plonk
+	circuit.assertDecryption()
 
-	// gcm := NewGCM(circuit.api, &aes)
-	gcm := NewGCMlu(circuit.api, aes)
+	return circuit.assertData()
+}
 
-	// verify aes gcm of chunks
-	// gcm.Assert(circuit.Key, circuit.Iv, circuit.ChunkIndex, circuit.PlainChunks, circuit.CipherChunks)
-	gcm.Assert2(circuit.Key, circuit.Iv, circuit.ChunkIndex, circuit.PlainChunks, circuit.CipherChunks)
+// decryption of the chunks and optional tag verification
+func (circuit *Tls13Record) assertDecryption() {
+
+	// per-record nonce
+	nonce := circuit.Iv
+	if circuit.SequenceNumber != nil {
+		nonce = RecordNonce(circuit.api, circuit.Iv, circuit.SequenceNumber)
+	}
+
+	if circuit.CipherSuite.IsAESGCM() {
+
+		// aes circuit
+		//aes := NewAES128(circuit.api) // groth16
+		// gcm := NewGCM(circuit.api, &aes)
+		gcm := circuit.CipherSuite.newGCM(circuit.api) // plonk
+
+		// verify aes gcm of chunks
+		// gcm.Assert(circuit.Key, circuit.Iv, circuit.ChunkIndex, circuit.PlainChunks, circuit.CipherChunks)
+		gcm.Assert2(circuit.Key, nonce, circuit.ChunkIndex, circuit.PlainChunks, circuit.CipherChunks)
+
+		// verify authentication tag if tag params are set
+		if len(circuit.Aad) > 0 {
+			circuit.api.AssertIsEqual(circuit.ChunkIndex, 2)
+			gcm.AssertTag(circuit.Key, nonce, circuit.Aad, circuit.CipherChunks, circuit.Tag)
+		}
+	} else {
+
+		// chacha20 keystream of chunks, ChunkIndex is the block counter
+		aead := NewChaCha20Poly1305(circuit.api)
+		aead.Assert(circuit.Key, nonce, circuit.ChunkIndex, circuit.PlainChunks, circuit.CipherChunks)
+
+		// verify poly1305 tag if tag params are set, the record keystream starts at block 1
+		if len(circuit.Aad) > 0 {
+			circuit.api.AssertIsEqual(circuit.ChunkIndex, 1)
+			aead.AssertTag(circuit.Key, nonce, circuit.Aad, circuit.CipherChunks, circuit.Tag)
+		}
+	}
+}
+
+// data checks over the verified plaintext
+func (circuit *Tls13Record) assertData() error {
+
+	// redacted plaintext of the verified record
+	if len(circuit.Mask) > 0 {
+		if len(circuit.Mask) != len(circuit.PlainChunks) || len(circuit.Revealed) != len(circuit.PlainChunks) {
+			return errors.New("disclosure mask must cover the plaintext")
+		}
+		AssertRedacted(circuit.api, circuit.PlainChunks, circuit.Mask, circuit.Revealed)
+	}
+
+	// policy over several fields of the verified plaintext
+	if circuit.Policy != nil {
+		if circuit.Commitment != nil {
+			return errors.New("value commitments are not supported with policies")
+		}
+		return circuit.assertPolicy()
+	}
 
 	// continue with verified plaintext, extract substring from it, and perform constraint check
-	extractedSubstring := circuit.PlainChunks[circuit.SubstringStart:circuit.SubstringEnd]
-	SubstringMatch(circuit.api, circuit.Substring, extractedSubstring, 0, len(circuit.Substring))
+	var valueInteger frontend.Variable
+	if circuit.Offsets != nil {
+
+		// lookup based extraction at witness-dependent offsets
+		offsets := circuit.Offsets
+		extractor := NewExtractor(circuit.api, circuit.PlainChunks, max(len(circuit.Substring), offsets.MaxValueLen))
+		if circuit.Pattern != "" {
+			if err := circuit.assertRegex(offsets.ValueStart, offsets.ValueEnd); err != nil {
+				return err
+			}
+		} else if len(circuit.KeyPath) > 0 {
+			circuit.assertKeyPath(offsets.ValueStart, offsets.ValueEnd)
+		} else {
+			circuit.api.AssertIsEqual(circuit.api.Sub(offsets.SubstringEnd, offsets.SubstringStart), len(circuit.Substring))
+			extractedSubstring := extractor.Substring(offsets.SubstringStart, len(circuit.Substring))
+			SubstringMatch(circuit.api, circuit.Substring, extractedSubstring, 0, len(circuit.Substring))
+
+			// value directly follows the substring and is closed by a quote
+			circuit.api.AssertIsEqual(offsets.ValueStart, circuit.api.Add(offsets.SubstringEnd, 2))
+			separator := extractor.Substring(offsets.SubstringEnd, 2)
+			circuit.api.AssertIsEqual(separator[0], ':')
+			circuit.api.AssertIsEqual(separator[1], '"')
+			closing := extractor.Substring(offsets.ValueEnd, 1)
+			circuit.api.AssertIsEqual(closing[0], '"')
+		}
+
+		// convert string value to integer
+		if circuit.Decimal {
+			decimal := extractor.Decimal(offsets.ValueStart, offsets.ValueEnd, offsets.MaxValueLen, circuit.Scale)
+			valueInteger = decimal.Value(circuit.api)
+		} else {
+			valueInteger = extractor.Value(offsets.ValueStart, offsets.ValueEnd, offsets.MaxValueLen)
+		}
+	} else {
+
+		if circuit.Pattern != "" {
+			if err := circuit.assertRegex(circuit.ValueStart, circuit.ValueEnd); err != nil {
+				return err
+			}
+		} else if len(circuit.KeyPath) > 0 {
+			circuit.assertKeyPath(circuit.ValueStart, circuit.ValueEnd)
+		} else {
+			extractedSubstring := circuit.PlainChunks[circuit.SubstringStart:circuit.SubstringEnd]
+			SubstringMatch(circuit.api, circuit.Substring, extractedSubstring, 0, len(circuit.Substring))
+		}
+
+		// convert string value to integer
+		valueString := circuit.PlainChunks[circuit.ValueStart:circuit.ValueEnd]
+		if circuit.Decimal {
+			decimal := StringToDecimal(circuit.api, valueString, circuit.Scale)
+			valueInteger = decimal.Value(circuit.api)
+		} else {
+			valueInteger = StringToInt(circuit.api, valueString)
+		}
+	}
 
-	// convert string value to integer
-	valueString := circuit.PlainChunks[circuit.ValueStart:circuit.ValueEnd]
-	valueInteger := StringToInt(circuit.api, valueString)
+	// commitment to the value
+	if circuit.Commitment != nil {
+		circuit.api.AssertIsEqual(ValueCommitment(circuit.api, valueInteger, circuit.Salt), circuit.Commitment)
+	}
 
 	// data constraint checks
-	GreaterThan(circuit.api, valueInteger, circuit.Threshold)
+	if circuit.Threshold == nil {
+		if circuit.Commitment == nil {
+			return errors.New("record without threshold or value commitment")
+		}
+	} else if circuit.Bits > 0 {
+		value, threshold := valueInteger, circuit.Threshold
+		if circuit.Decimal {
+			bias := new(big.Int).Lsh(big.NewInt(1), uint(circuit.Bits-1))
+			value = circuit.api.Add(value, bias)
+			threshold = circuit.api.Add(threshold, bias)
+		}
+		if circuit.Result != nil {
+			result := IsComparison(circuit.api, circuit.Comparison, value, threshold, circuit.Bits)
+			circuit.api.AssertIsEqual(result, circuit.Result)
+		} else {
+			AssertComparison(circuit.api, circuit.Comparison, value, threshold, circuit.Bits)
+		}
+	} else if circuit.Result != nil {
+		return errors.New("predicate result requires a comparison bit width")
+	} else if circuit.Decimal {
+		DecimalGreaterThan(circuit.api, valueInteger, circuit.Threshold)
+	} else {
+		GreaterThan(circuit.api, valueInteger, circuit.Threshold)
+	}
+
+	return nil
+}
+
+// json key path check of the value position
+func (circuit *Tls13Record) assertKeyPath(valueStart, valueEnd frontend.Variable) {
+	maxKeyLen := 0
+	for _, key := range circuit.KeyPath {
+		maxKeyLen = max(maxKeyLen, len(key))
+	}
+	scanner := NewJsonScanner(circuit.api, circuit.PlainChunks, maxKeyLen)
+	scanner.AssertKeyPath(circuit.KeyPath, circuit.KeyStarts, valueStart, valueEnd)
+}
+
+// regex match with the capture group at the value position
+func (circuit *Tls13Record) assertRegex(valueStart, valueEnd frontend.Variable) error {
+	regex, err := NewRegex(circuit.api, circuit.Pattern)
+	if err != nil {
+		return err
+	}
+	regex.AssertMatch(circuit.PlainChunks, circuit.MatchStart, valueStart, valueEnd, circuit.MatchEnd)
+	return nil
+}
 
+// policy evaluation over fixed field offsets, each field follows its key
+func (circuit *Tls13Record) assertPolicy() error {
+	fields := make([][]frontend.Variable, len(circuit.PolicyFields))
+	for i, field := range circuit.PolicyFields {
+		if field.Key == "" {
+			return errors.New("policy field without key")
+		}
+		if field.Start < len(field.Key) || field.Start > field.End || field.End > len(circuit.PlainChunks) {
+			return errors.New("policy field out of range")
+		}
+		key := constVariables([]byte(field.Key))
+		SubstringMatch(circuit.api, key, circuit.PlainChunks[field.Start-len(key):field.Start], 0, len(key))
+		fields[i] = circuit.PlainChunks[field.Start:field.End]
+	}
+	policy := NewPolicy(circuit.api, fields, circuit.Thresholds, circuit.Texts, circuit.Bits)
+	if circuit.Result == nil {
+		return policy.Assert(*circuit.Policy)
+	}
+	result, err := policy.Evaluate(*circuit.Policy)
+	if err != nil {
+		return err
+	}
+	circuit.api.AssertIsEqual(result, circuit.Result)
 	return nil
 }
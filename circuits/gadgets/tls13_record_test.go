@@ -0,0 +1,106 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// record fixture of the tests, key and iv of TLS_AES_128_GCM_SHA256
+var (
+	recordKey = mustHex("2872658573f95e87550cb26374e5f667")
+	recordIv  = mustHex("a54613bf2801a84ce693d0a0")
+	// 32 byte key of TLS_CHACHA20_POLY1305_SHA256 records
+	recordChaChaKey = mustHex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+)
+
+const recordPlaintext = `{"name":"bitcoin","price":"38002"}`
+
+// aes gcm of the fixture key
+func recordAEAD(t *testing.T) cipher.AEAD {
+	block, err := aes.NewCipher(recordKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aesgcm
+}
+
+// chacha20-poly1305 of the fixture chacha key
+func recordChaChaAEAD(t *testing.T) cipher.AEAD {
+	aead, err := chacha20poly1305.New(recordChaChaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+// key, iv and chunks of plaintext encrypted from block counter 2 of record 0
+func recordWitness(t *testing.T, plaintext string) ([16]frontend.Variable, [12]frontend.Variable, []frontend.Variable, []frontend.Variable) {
+	var key [16]frontend.Variable
+	var iv [12]frontend.Variable
+	for i := 0; i < 16; i++ {
+		key[i] = recordKey[i]
+	}
+	for i := 0; i < 12; i++ {
+		iv[i] = recordIv[i]
+	}
+	ciphertext := recordAEAD(t).Seal(nil, recordIv, []byte(plaintext), nil)[:len(plaintext)]
+	return key, iv, toVariables([]byte(plaintext)), toVariables(ciphertext)
+}
+
+// RecordWrapper checking the value following substring in plaintext
+func newRecordCircuit(plaintext, substring, value string) *RecordWrapper {
+	substringStart := strings.Index(plaintext, substring)
+	valueStart := strings.Index(plaintext, value)
+	return &RecordWrapper{
+		PlainChunks:    make([]frontend.Variable, len(plaintext)),
+		CipherChunks:   make([]frontend.Variable, len(plaintext)),
+		Substring:      make([]frontend.Variable, len(substring)),
+		SubstringStart: substringStart,
+		SubstringEnd:   substringStart + len(substring),
+		ValueStart:     valueStart,
+		ValueEnd:       valueStart + len(value),
+	}
+}
+
+func newRecordAssignment(t *testing.T, plaintext, substring, value string, threshold frontend.Variable) *RecordWrapper {
+	assignment := newRecordCircuit(plaintext, substring, value)
+	assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, plaintext)
+	assignment.SequenceNumber = 0
+	assignment.ChunkIndex = 2
+	assignment.Substring = toVariables([]byte(substring))
+	assignment.Threshold = threshold
+	return assignment
+}
+
+func toVariables(in []byte) []frontend.Variable {
+	out := make([]frontend.Variable, len(in))
+	for i := range in {
+		out[i] = in[i]
+	}
+	return out
+}
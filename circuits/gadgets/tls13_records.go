@@ -0,0 +1,253 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// inner content type of application data records
+const recordApplicationData = 0x17
+
+// one record of a multi-record proof. records with aad and tag are full
+// records whose plaintext ends with the inner content type, others are
+// chunks starting at ChunkIndex
+type RecordChunk struct {
+	SequenceNumber frontend.Variable `gnark:",public"`
+	ChunkIndex     frontend.Variable `gnark:",public"`
+	PlainChunks    []frontend.Variable
+	CipherChunks   []frontend.Variable `gnark:",public"`
+	Aad            []frontend.Variable `gnark:",public"`
+	Tag            []frontend.Variable `gnark:",public"`
+}
+
+// multi-record evaluation, offsets refer to the concatenated plaintext
+type RecordsWrapper struct {
+	Key            [16]frontend.Variable
+	Iv             [12]frontend.Variable `gnark:",public"`
+	Records        []RecordChunk
+	Substring      []frontend.Variable `gnark:",public"`
+	SubstringStart int                 `gnark:",public"`
+	SubstringEnd   int                 `gnark:",public"`
+	ValueStart     int                 `gnark:",public"`
+	ValueEnd       int                 `gnark:",public"`
+	Threshold      frontend.Variable   `gnark:",public"`
+	Comparison     Comparison
+	Bits           int
+}
+
+// Define declares the circuit's constraints
+func (circuit *RecordsWrapper) Define(api frontend.API) error {
+
+	records := NewTls13Records(api)
+	records.SetParams(circuit.Key[:], circuit.Iv, circuit.Records)
+	records.SetDataParams(
+		circuit.Substring,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	records.Data().SetComparison(circuit.Comparison, circuit.Bits)
+
+	return records.Assert()
+}
+
+// multi-record oracle, one kdc derives the key of all records
+type Tls13RecordsOracleWrapper struct {
+	CipherSuite CipherSuite
+	// kdc params, sized by cipher suite hash
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable `gnark:",public"`
+	MSin                   []frontend.Variable `gnark:",public"`
+	SATSin                 []frontend.Variable `gnark:",public"`
+	TkSAPPin               []frontend.Variable `gnark:",public"`
+	IvSAPPin               []frontend.Variable `gnark:",public"`
+	// optional key exchange replacing DHSin and IntermediateHashHSopad
+	KeyExchange  KeyExchange
+	ClientSecret []frontend.Variable
+	ServerShare  []frontend.Variable `gnark:",public"`
+	// record params
+	Iv             [12]frontend.Variable `gnark:",public"`
+	Records        []RecordChunk
+	Substring      []frontend.Variable `gnark:",public"`
+	SubstringStart int                 `gnark:",public"`
+	SubstringEnd   int                 `gnark:",public"`
+	ValueStart     int                 `gnark:",public"`
+	ValueEnd       int                 `gnark:",public"`
+	Threshold      frontend.Variable   `gnark:",public"`
+	Comparison     Comparison
+	Bits           int
+}
+
+// Define declares the circuit's constraints
+func (circuit *Tls13RecordsOracleWrapper) Define(api frontend.API) error {
+
+	// derive key and iv
+	tls13_kdc := NewTls13Kdc(api)
+	tls13_kdc.SetCipherSuite(circuit.CipherSuite)
+	tls13_kdc.SetParams(
+		circuit.IntermediateHashHSopad,
+		circuit.MSin,
+		circuit.SATSin,
+		circuit.TkSAPPin,
+		circuit.IvSAPPin,
+		circuit.DHSin,
+	)
+	if circuit.KeyExchange != KeyExchangeNone {
+		sharedSecret, err := ECDHE(api, circuit.KeyExchange, circuit.ClientSecret, circuit.ServerShare)
+		if err != nil {
+			return err
+		}
+		tls13_kdc.SetSharedSecret(sharedSecret)
+	}
+	tk, iv, err := tls13_kdc.Derive()
+	if err != nil {
+		return err
+	}
+
+	// record iv must match the derived iv
+	for i := 0; i < 12; i++ {
+		api.AssertIsEqual(circuit.Iv[i], iv[i])
+	}
+
+	records := NewTls13Records(api)
+	records.SetCipherSuite(circuit.CipherSuite)
+	records.SetParams(tk, circuit.Iv, circuit.Records)
+	records.SetDataParams(
+		circuit.Substring,
+		circuit.Threshold,
+		circuit.SubstringStart,
+		circuit.SubstringEnd,
+		circuit.ValueStart,
+		circuit.ValueEnd,
+	)
+	records.Data().SetComparison(circuit.Comparison, circuit.Bits)
+
+	return records.Assert()
+}
+
+// several records of one traffic key, the data checks of Tls13Record run over
+// the concatenated plaintext. sequence numbers are consecutive and all records
+// but the last are full records without padding, so the concatenation is the
+// application data stream. the last record may be a chunk from its start
+type Tls13Records struct {
+	api         frontend.API
+	CipherSuite CipherSuite
+	Key         []frontend.Variable
+	Iv          [12]frontend.Variable // `gnark:",public"`
+	Records     []RecordChunk
+	data        Tls13Record
+}
+
+func NewTls13Records(api frontend.API) Tls13Records {
+	return Tls13Records{api: api, data: NewTls13Record(api)}
+}
+
+func (circuit *Tls13Records) SetParams(key []frontend.Variable, iv [12]frontend.Variable, records []RecordChunk) {
+	circuit.Key = key
+	circuit.Iv = iv
+	circuit.Records = records
+}
+
+// defaults to TLS_AES_128_GCM_SHA256, key length must match the cipher suite
+func (circuit *Tls13Records) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+	circuit.data.SetCipherSuite(cipherSuite)
+}
+
+// substring and value offsets into the concatenated plaintext
+func (circuit *Tls13Records) SetDataParams(substring []frontend.Variable, threshold frontend.Variable, substringStart, substringEnd, valueStart, valueEnd int) {
+	circuit.data.Substring = substring
+	circuit.data.Threshold = threshold
+	circuit.data.SubstringStart = substringStart
+	circuit.data.SubstringEnd = substringEnd
+	circuit.data.ValueStart = valueStart
+	circuit.data.ValueEnd = valueEnd
+}
+
+// Data returns the record gadget of the concatenated plaintext, its optional
+// settings such as offsets, key paths, policies, disclosure and commitments
+// apply as for single records
+func (circuit *Tls13Records) Data() *Tls13Record {
+	return &circuit.data
+}
+
+// Assert decrypts every record and checks the data of the concatenation
+func (circuit *Tls13Records) Assert() error {
+
+	api := circuit.api
+
+	if len(circuit.Records) == 0 {
+		return errors.New("multi-record proof without records")
+	}
+
+	// first keystream block of a record
+	counter := 2
+	if !circuit.CipherSuite.IsAESGCM() {
+		counter = 1
+	}
+
+	var plaintext []frontend.Variable
+	for i, r := range circuit.Records {
+
+		if len(r.PlainChunks) == 0 || len(r.PlainChunks) != len(r.CipherChunks) {
+			return errors.New("record plaintext and ciphertext lengths differ")
+		}
+		full := len(r.Aad) > 0
+		if full && len(r.Tag) != 16 {
+			return errors.New("record tag must have 16 bytes")
+		}
+		if !full && i < len(circuit.Records)-1 {
+			return errors.New("all records but the last must be full records with tag")
+		}
+
+		// consecutive records
+		if i > 0 {
+			api.AssertIsEqual(r.SequenceNumber, api.Add(circuit.Records[i-1].SequenceNumber, 1))
+			api.AssertIsEqual(r.ChunkIndex, counter)
+		}
+
+		record := NewTls13Record(api)
+		record.SetCipherSuite(circuit.CipherSuite)
+		record.SetParams(circuit.Key, circuit.Iv, r.PlainChunks, r.CipherChunks, nil, r.ChunkIndex, nil, 0, 0, 0, 0)
+		record.SetSequenceNumber(r.SequenceNumber)
+		if full {
+			var tag [16]frontend.Variable
+			copy(tag[:], r.Tag)
+			record.SetTagParams(r.Aad, tag)
+		}
+		record.assertDecryption()
+
+		// the inner content type is not part of the data
+		if full {
+			api.AssertIsEqual(r.PlainChunks[len(r.PlainChunks)-1], recordApplicationData)
+			plaintext = append(plaintext, r.PlainChunks[:len(r.PlainChunks)-1]...)
+		} else {
+			plaintext = append(plaintext, r.PlainChunks...)
+		}
+	}
+
+	circuit.data.Key = circuit.Key
+	circuit.data.Iv = circuit.Iv
+	circuit.data.PlainChunks = plaintext
+
+	return circuit.data.assertData()
+}
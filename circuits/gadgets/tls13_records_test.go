@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestRecords(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	aesgcm := recordAEAD(t)
+
+	// the price is split across a full record and a chunk of the next one
+	response := `HTTP/1.1 200 OK` + "\r\n\r\n" + recordPlaintext
+	split := strings.Index(response, "38") + 2
+	substring := "\"price\""
+	substringStart := strings.Index(response, substring)
+	valueStart := strings.Index(response, "38002")
+
+	nonce := func(seq byte) []byte {
+		n := append([]byte{}, recordIv...)
+		n[11] ^= seq
+		return n
+	}
+
+	// full record 3 with inner content type and tag
+	first := append([]byte(response[:split]), 0x17)
+	aad := []byte{0x17, 0x03, 0x03, 0x00, byte(len(first) + 16)}
+	sealed := aesgcm.Seal(nil, nonce(3), first, aad)
+
+	// first chunk of record 4
+	second := []byte(response[split:])
+	secondCipher := aesgcm.Seal(nil, nonce(4), second, nil)[:len(second)]
+
+	newCircuit := func() *RecordsWrapper {
+		return &RecordsWrapper{
+			Records: []RecordChunk{
+				{
+					PlainChunks:  make([]frontend.Variable, len(first)),
+					CipherChunks: make([]frontend.Variable, len(first)),
+					Aad:          make([]frontend.Variable, 5),
+					Tag:          make([]frontend.Variable, 16),
+				},
+				{
+					PlainChunks:  make([]frontend.Variable, len(second)),
+					CipherChunks: make([]frontend.Variable, len(second)),
+				},
+			},
+			Substring:      make([]frontend.Variable, len(substring)),
+			SubstringStart: substringStart,
+			SubstringEnd:   substringStart + len(substring),
+			ValueStart:     valueStart,
+			ValueEnd:       valueStart + 5,
+			Comparison:     GT,
+			Bits:           32,
+		}
+	}
+	newAssignment := func(threshold int, sequenceNumbers [2]int) *RecordsWrapper {
+		assignment := newCircuit()
+		for i := 0; i < 16; i++ {
+			assignment.Key[i] = recordKey[i]
+		}
+		for i := 0; i < 12; i++ {
+			assignment.Iv[i] = recordIv[i]
+		}
+		assignment.Records[0] = RecordChunk{
+			SequenceNumber: sequenceNumbers[0],
+			ChunkIndex:     2,
+			PlainChunks:    toVariables(first),
+			CipherChunks:   toVariables(sealed[:len(first)]),
+			Aad:            toVariables(aad),
+			Tag:            toVariables(sealed[len(first):]),
+		}
+		assignment.Records[1] = RecordChunk{
+			SequenceNumber: sequenceNumbers[1],
+			ChunkIndex:     2,
+			PlainChunks:    toVariables(second),
+			CipherChunks:   toVariables(secondCipher),
+			Aad:            []frontend.Variable{},
+			Tag:            []frontend.Variable{},
+		}
+		assignment.Substring = toVariables([]byte(substring))
+		assignment.Threshold = threshold
+		return assignment
+	}
+
+	// price > 38000 over the concatenated plaintext
+	assert.SolvingSucceeded(newCircuit(), newAssignment(38000, [2]int{3, 4}), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(), newAssignment(38002, [2]int{3, 4}), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// records must be consecutive and decrypt under their own nonces
+	assert.SolvingFailed(newCircuit(), newAssignment(38000, [2]int{3, 5}), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(), newAssignment(38000, [2]int{4, 5}), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"errors"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// deterministic automaton over bytes, state 0 is the start state
+type DFA struct {
+	Transitions [][256]int
+	Accept      []bool
+}
+
+// CompileDFA compiles a regular expression without anchors to a dfa at circuit build time,
+// bytes are matched as runes in [0, 255] so patterns should stay ascii
+func CompileDFA(re *syntax.Regexp) (DFA, error) {
+
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return DFA{}, err
+	}
+	for _, inst := range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth {
+			return DFA{}, errors.New("anchors and word boundaries are not supported")
+		}
+	}
+
+	// epsilon closure of instruction sets
+	closure := func(pcs []uint32) ([]uint32, bool) {
+		seen := map[uint32]bool{}
+		stack := append([]uint32{}, pcs...)
+		accept := false
+		var out []uint32
+		for len(stack) > 0 {
+			pc := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[pc] {
+				continue
+			}
+			seen[pc] = true
+			inst := prog.Inst[pc]
+			switch inst.Op {
+			case syntax.InstAlt, syntax.InstAltMatch:
+				stack = append(stack, inst.Out, inst.Arg)
+			case syntax.InstCapture, syntax.InstNop:
+				stack = append(stack, inst.Out)
+			case syntax.InstMatch:
+				accept = true
+			case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+				out = append(out, pc)
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out, accept
+	}
+	key := func(pcs []uint32) string {
+		var sb strings.Builder
+		for _, pc := range pcs {
+			sb.WriteString(strconv.Itoa(int(pc)))
+			sb.WriteString(",")
+		}
+		return sb.String()
+	}
+
+	// subset construction, the empty set is the dead state
+	var dfa DFA
+	var sets [][]uint32
+	index := map[string]int{}
+	add := func(pcs []uint32, accept bool) int {
+		k := key(pcs) + strconv.FormatBool(accept)
+		if i, ok := index[k]; ok {
+			return i
+		}
+		index[k] = len(sets)
+		sets = append(sets, pcs)
+		dfa.Transitions = append(dfa.Transitions, [256]int{})
+		dfa.Accept = append(dfa.Accept, accept)
+		return len(sets) - 1
+	}
+	add(closure([]uint32{uint32(prog.Start)}))
+
+	for s := 0; s < len(sets); s++ {
+		for c := 0; c < 256; c++ {
+			var next []uint32
+			for _, pc := range sets[s] {
+				if prog.Inst[pc].MatchRune(rune(c)) {
+					next = append(next, prog.Inst[pc].Out)
+				}
+			}
+			dfa.Transitions[s][c] = add(closure(next))
+		}
+	}
+
+	return dfa, nil
+}
+
+// states from which an accepting state is reachable
+func (dfa DFA) live() []bool {
+	live := append([]bool{}, dfa.Accept...)
+	for changed := true; changed; {
+		changed = false
+		for s := range dfa.Transitions {
+			if live[s] {
+				continue
+			}
+			for c := 0; c < 256; c++ {
+				if live[dfa.Transitions[s][c]] {
+					live[s] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return live
+}
+
+// the split between adjacent segments is unique if no byte extends an
+// accepted left segment and also starts the right segment
+func uniqueSplit(left, right DFA) bool {
+	leftLive := left.live()
+	rightLive := right.live()
+	for s := range left.Transitions {
+		if !left.Accept[s] {
+			continue
+		}
+		for c := 0; c < 256; c++ {
+			if leftLive[left.Transitions[s][c]] && rightLive[right.Transitions[0][c]] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// regex gadget, the pattern is split into prefix, capture group and suffix.
+// the pattern must match plaintext[matchStart:matchEnd] and the group
+// plaintext[groupStart:groupEnd]. patterns without group capture the full match.
+// the prover picks all offsets, so captures are not leftmost-greedy as in go's
+// regexp. patterns where the group boundaries are not unique, e.g. a group
+// followed by a suffix starting with bytes the group may contain, are rejected.
+// matchStart and matchEnd are free as well, the pattern should pin the group
+// with literal delimiters on both sides such as "price":"([0-9]+)".
+type Regex struct {
+	api    frontend.API
+	prefix DFA
+	group  DFA
+	suffix DFA
+}
+
+func NewRegex(api frontend.API, pattern string) (Regex, error) {
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return Regex{}, err
+	}
+
+	// split top level concatenation at the capture group
+	var prefix, group, suffix []*syntax.Regexp
+	switch {
+	case re.Op == syntax.OpCapture:
+		group = re.Sub
+	case re.Op == syntax.OpConcat:
+		captureIdx := -1
+		for i, sub := range re.Sub {
+			if sub.Op == syntax.OpCapture {
+				if captureIdx >= 0 {
+					return Regex{}, errors.New("at most one capture group is supported")
+				}
+				captureIdx = i
+			}
+		}
+		if captureIdx < 0 {
+			group = re.Sub
+		} else {
+			prefix = re.Sub[:captureIdx]
+			group = re.Sub[captureIdx].Sub
+			suffix = re.Sub[captureIdx+1:]
+		}
+	default:
+		group = []*syntax.Regexp{re}
+	}
+
+	r := Regex{api: api}
+	for _, part := range []struct {
+		subs []*syntax.Regexp
+		dfa  *DFA
+	}{{prefix, &r.prefix}, {group, &r.group}, {suffix, &r.suffix}} {
+		concat := &syntax.Regexp{Op: syntax.OpConcat, Sub: part.subs, Flags: re.Flags}
+		if len(part.subs) == 0 {
+			concat = &syntax.Regexp{Op: syntax.OpEmptyMatch}
+		}
+		dfa, err := CompileDFA(concat)
+		if err != nil {
+			return Regex{}, err
+		}
+		*part.dfa = dfa
+	}
+
+	if !uniqueSplit(r.prefix, r.group) || !uniqueSplit(r.group, r.suffix) {
+		return Regex{}, errors.New("capture group boundaries are ambiguous, the bytes around the group must not continue it")
+	}
+
+	return r, nil
+}
+
+// AssertMatch checks prefix, group and suffix on the adjacent plaintext segments
+func (r *Regex) AssertMatch(plaintext []frontend.Variable, matchStart, groupStart, groupEnd, matchEnd frontend.Variable) {
+
+	// transition lookups require bytes
+	for i := range plaintext {
+		r.api.ToBinary(plaintext[i], 8)
+	}
+
+	r.assertSegment(r.prefix, plaintext, matchStart, groupStart)
+	r.assertSegment(r.group, plaintext, groupStart, groupEnd)
+	r.assertSegment(r.suffix, plaintext, groupEnd, matchEnd)
+}
+
+// runs the dfa over plaintext[start:end] and checks the final state accepts
+func (r *Regex) assertSegment(dfa DFA, plaintext []frontend.Variable, start, end frontend.Variable) {
+
+	api := r.api
+
+	// transition table indexed by state*256 + byte
+	transitions := logderivlookup.New(api)
+	for s := range dfa.Transitions {
+		for c := 0; c < 256; c++ {
+			transitions.Insert(dfa.Transitions[s][c])
+		}
+	}
+	accept := logderivlookup.New(api)
+	for s := range dfa.Accept {
+		if dfa.Accept[s] {
+			accept.Insert(1)
+		} else {
+			accept.Insert(0)
+		}
+	}
+
+	// state only changes inside the segment, start and end must lie in [0, len(plaintext)]
+	state := frontend.Variable(0)
+	inRange := frontend.Variable(0)
+	startFound := frontend.Variable(0)
+	endFound := frontend.Variable(0)
+	for i := 0; i <= len(plaintext); i++ {
+		isStart := api.IsZero(api.Sub(start, i))
+		isEnd := api.IsZero(api.Sub(end, i))
+		startFound = api.Add(startFound, isStart)
+		endFound = api.Add(endFound, isEnd)
+		inRange = api.Sub(api.Add(inRange, isStart), isEnd)
+		api.AssertIsBoolean(inRange)
+
+		if i < len(plaintext) {
+			next := transitions.Lookup(api.Add(api.Mul(state, 256), plaintext[i]))
+			state = api.Select(inRange, next[0], state)
+		}
+	}
+	api.AssertIsEqual(startFound, 1)
+	api.AssertIsEqual(endFound, 1)
+
+	accepted := accept.Lookup(state)
+	api.AssertIsEqual(accepted[0], 1)
+}
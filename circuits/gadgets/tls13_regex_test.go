@@ -0,0 +1,130 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestCompileDFA(t *testing.T) {
+
+	patterns := []string{`"price":"[0-9]+(\.[0-9]{1,2})?"`, `(?i)status=(active|verified)`, `a*b|c+`, `[^"]*x`}
+	inputs := []string{`"price":"38002"`, `"price":"38002.15"`, `"price":"38002.155"`, `"price":""`, `STATUS=Active`,
+		`status=verified`, `status=deleted`, ``, `aaab`, `b`, `ccc`, `ac`, `abcx`, `ab"x`}
+
+	for _, pattern := range patterns {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dfa, err := CompileDFA(re)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := regexp.MustCompile("^(?:" + pattern + ")$")
+		for _, in := range inputs {
+			state := 0
+			for i := 0; i < len(in); i++ {
+				state = dfa.Transitions[state][in[i]]
+			}
+			if dfa.Accept[state] != expected.MatchString(in) {
+				t.Errorf("pattern %s on %q: got %v", pattern, in, dfa.Accept[state])
+			}
+		}
+	}
+
+	// anchors are rejected
+	re, _ := syntax.Parse(`^abc`, syntax.Perl)
+	if _, err := CompileDFA(re); err == nil {
+		t.Error("expected error for anchored pattern")
+	}
+}
+
+func TestRegexSplit(t *testing.T) {
+
+	// group boundaries are unique
+	for _, pattern := range []string{`"price":"([0-9]+)"`, `"price":"([0-9]+)(?:\.[0-9]+)?"`, `status=(active|verified)&`} {
+		if _, err := NewRegex(nil, pattern); err != nil {
+			t.Errorf("pattern %s: %v", pattern, err)
+		}
+	}
+
+	// the prover could move the group boundaries, e.g. capture "3" out of "38002"
+	for _, pattern := range []string{`"price":"([0-9]+)[0-9]*"`, `"price":"[0-9]*([0-9]+)"`, `a*([ab]+)"`} {
+		if _, err := NewRegex(nil, pattern); err == nil {
+			t.Errorf("pattern %s: expected error for ambiguous group boundaries", pattern)
+		}
+	}
+}
+
+func TestRecordRegex(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	pattern := `"price":"([0-9]+)"`
+
+	newCircuit := func(length int) *RecordRegexWrapper {
+		return &RecordRegexWrapper{
+			PlainChunks:  make([]frontend.Variable, length),
+			CipherChunks: make([]frontend.Variable, length),
+			Pattern:      pattern,
+			MaxValueLen:  8,
+		}
+	}
+	newAssignment := func(plaintext string, matchStart, valueStart, valueLen int) *RecordRegexWrapper {
+		assignment := newCircuit(len(plaintext))
+		assignment.SequenceNumber = 0
+		assignment.ChunkIndex = 2
+		assignment.Threshold = 38000
+		assignment.MatchStart = matchStart
+		assignment.MatchEnd = valueStart + valueLen + 1
+		assignment.ValueStart = valueStart
+		assignment.ValueEnd = valueStart + valueLen
+		assignment.Key, assignment.Iv, assignment.PlainChunks, assignment.CipherChunks = recordWitness(t, plaintext)
+		return assignment
+	}
+
+	// same circuit, match at different positions with different value lengths
+	for _, plaintext := range []string{
+		`{"id":7,"price":"38002","currency":"EUR"}`,
+		`{"currency":"EUR","price":"1038002"}      `,
+	} {
+		matchStart := strings.Index(plaintext, `"price"`)
+		valueStart := matchStart + len(`"price":"`)
+		valueLen := strings.Index(plaintext[valueStart:], `"`)
+		assignment := newAssignment(plaintext, matchStart, valueStart, valueLen)
+		assert.SolvingSucceeded(newCircuit(len(plaintext)), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+
+	// capture group must not cover the closing quote
+	plaintext := `{"id":7,"price":"38002","currency":"EUR"}`
+	matchStart := strings.Index(plaintext, `"price"`)
+	assignment := newAssignment(plaintext, matchStart, matchStart+9, 6)
+	assert.SolvingFailed(newCircuit(len(plaintext)), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// non matching prefix must fail
+	plaintext = `{"id":7,"prize":"38002","currency":"EUR"}`
+	assignment = newAssignment(plaintext, matchStart, matchStart+9, 5)
+	assert.SolvingFailed(newCircuit(len(plaintext)), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
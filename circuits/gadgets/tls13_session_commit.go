@@ -21,18 +21,23 @@ import (
 )
 
 type Tls13SessionCommitWrapper struct {
-	// kdc params
-	DHSin                  [64]frontend.Variable
-	IntermediateHashHSopad [32]frontend.Variable `gnark:",public"`
-	MSin                   [32]frontend.Variable `gnark:",public"`
-	SATSin                 [32]frontend.Variable `gnark:",public"`
-	TkSAPPin               [32]frontend.Variable `gnark:",public"`
+	CipherSuite CipherSuite
+	// kdc params, sized by cipher suite hash
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable   `gnark:",public"`
+	MSin                   []frontend.Variable   `gnark:",public"`
+	SATSin                 []frontend.Variable   `gnark:",public"`
+	TkSAPPin               []frontend.Variable   `gnark:",public"`
+	IvSAPPin               []frontend.Variable   `gnark:",public"`
 	TkCommit               [32]frontend.Variable `gnark:",public"`
 	// authtag params
 	IvCounter [16]frontend.Variable `gnark:",public"`
 	Zeros     [16]frontend.Variable `gnark:",public"`
 	ECB0      [16]frontend.Variable `gnark:",public"`
 	ECBK      [16]frontend.Variable `gnark:",public"`
+	// key commitment scheme and secret salt
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
 }
 
 // Define declares the circuit's constraints
@@ -42,11 +47,13 @@ func (circuit *Tls13SessionCommitWrapper) Define(api frontend.API) error {
 	session_commit := NewTls13SessionCommit(api)
 
 	// set data
+	session_commit.SetCipherSuite(circuit.CipherSuite)
 	session_commit.SetKdcParams(
 		circuit.IntermediateHashHSopad,
 		circuit.MSin,
 		circuit.SATSin,
 		circuit.TkSAPPin,
+		circuit.IvSAPPin,
 		circuit.TkCommit,
 		circuit.DHSin,
 	)
@@ -57,22 +64,23 @@ func (circuit *Tls13SessionCommitWrapper) Define(api frontend.API) error {
 		circuit.ECB0,
 		circuit.ECBK,
 	)
+	session_commit.SetCommitScheme(circuit.CommitScheme, circuit.KeySalt)
 
 	// verify commitment
-	session_commit.Assert()
-
-	return nil
+	return session_commit.Assert()
 }
 
 type Tls13SessionCommit struct {
-	api frontend.API
+	api         frontend.API
+	CipherSuite CipherSuite
 
 	// kdc params
-	DHSin                  [64]frontend.Variable
-	IntermediateHashHSopad [32]frontend.Variable // `gnark:",public"`
-	MSin                   [32]frontend.Variable // `gnark:",public"`
-	XATSin                 [32]frontend.Variable // `gnark:",public"`
-	TkXAPPin               [32]frontend.Variable // `gnark:",public"`
+	DHSin                  []frontend.Variable
+	IntermediateHashHSopad []frontend.Variable   // `gnark:",public"`
+	MSin                   []frontend.Variable   // `gnark:",public"`
+	XATSin                 []frontend.Variable   // `gnark:",public"`
+	TkXAPPin               []frontend.Variable   // `gnark:",public"`
+	IvXAPPin               []frontend.Variable   // `gnark:",public"`
 	TkCommit               [32]frontend.Variable // `gnark:",public"`
 
 	// authtag params
@@ -80,17 +88,27 @@ type Tls13SessionCommit struct {
 	Zeros     [16]frontend.Variable // `gnark:",public"`
 	ECB0      [16]frontend.Variable // `gnark:",public"`
 	ECBK      [16]frontend.Variable // `gnark:",public"`
+
+	// commitment params
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
 }
 
 func NewTls13SessionCommit(api frontend.API) Tls13SessionCommit {
 	return Tls13SessionCommit{api: api}
 }
 
-func (circuit *Tls13SessionCommit) SetKdcParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin, TkCommit [32]frontend.Variable, DHSin [64]frontend.Variable) {
+// defaults to TLS_AES_128_GCM_SHA256
+func (circuit *Tls13SessionCommit) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
+func (circuit *Tls13SessionCommit) SetKdcParams(IntermediateHashHSopad, MSin, XATSin, TkXAPPin, IvXAPPin []frontend.Variable, TkCommit [32]frontend.Variable, DHSin []frontend.Variable) {
 	circuit.IntermediateHashHSopad = IntermediateHashHSopad
 	circuit.MSin = MSin
 	circuit.XATSin = XATSin
 	circuit.TkXAPPin = TkXAPPin
+	circuit.IvXAPPin = IvXAPPin
 	circuit.TkCommit = TkCommit
 	circuit.DHSin = DHSin
 }
@@ -102,26 +120,44 @@ func (circuit *Tls13SessionCommit) SetAuthtagParams(ivCounter, zeros, ecb0, ecbk
 	circuit.ECBK = ecbk
 }
 
+// optional key commitment scheme, defaults to the unsalted sha256(tk). salted
+// schemes take a secret 32 byte salt
+func (circuit *Tls13SessionCommit) SetCommitScheme(scheme KeyCommitScheme, salt []frontend.Variable) {
+	circuit.CommitScheme = scheme
+	circuit.KeySalt = salt
+}
+
 // Define declares the circuit's constraints
-func (circuit *Tls13SessionCommit) Assert() {
+func (circuit *Tls13SessionCommit) Assert() error {
 
 	// kdc verification
 
-	// derive key
+	// derive key and iv
 	tls13_kdc := NewTls13Kdc(circuit.api)
+	tls13_kdc.SetCipherSuite(circuit.CipherSuite)
 	tls13_kdc.SetParams(
 		circuit.IntermediateHashHSopad,
 		circuit.MSin,
 		circuit.XATSin,
 		circuit.TkXAPPin,
+		circuit.IvXAPPin,
 		circuit.DHSin,
 	)
-	tk := tls13_kdc.Derive()
+	tk, iv, err := tls13_kdc.Derive()
+	if err != nil {
+		return err
+	}
+
+	// authtag iv must match the derived iv
+	for i := 0; i < 12; i++ {
+		circuit.api.AssertIsEqual(circuit.IvCounter[i], iv[i])
+	}
 
 	// compute key commitment
-	sha := NewSHA256(circuit.api)
-	sha.Write(tk)
-	commit := sha.Sum()
+	commit, err := KeyCommitment(circuit.api, circuit.CommitScheme, tk, circuit.KeySalt)
+	if err != nil {
+		return err
+	}
 
 	// constraints check
 	for i := 0; i < 32; i++ {
@@ -132,12 +168,9 @@ func (circuit *Tls13SessionCommit) Assert() {
 
 	// init
 	tag := NewTls13AuthTag(circuit.api)
-
-	// type conversion
-	var tk16 [16]frontend.Variable
-	copy(tk16[:], tk)
-	tag.SetParams(tk16, circuit.IvCounter, circuit.Zeros, circuit.ECB0, circuit.ECBK)
+	tag.SetCipherSuite(circuit.CipherSuite)
+	tag.SetParams(tk, circuit.IvCounter, circuit.Zeros, circuit.ECB0, circuit.ECBK)
 
 	// verify tag
-	tag.Assert()
+	return tag.Assert()
 }
@@ -18,6 +18,7 @@ package gadgets
 
 import (
 	"encoding/hex"
+	"errors"
 	"strings"
 	"time"
 
@@ -26,7 +27,12 @@ import (
 )
 
 // execution of circuit function of program
-func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Duration, error) {
+func EvaluateSessionCommit(backend string, compile bool, cipherSuite CipherSuite) (map[string]time.Duration, error) {
+
+	// the authtag gadget requires an aes gcm suite
+	if !cipherSuite.IsAESGCM() {
+		return nil, errors.New("unsupported cipher suite " + cipherSuite.String())
+	}
 
 	// kdc params
 	intermediateHashHSopad := "5113c2d6533a74ea90392417f726dc79c180819ad8a55bd809a5b38a0858b12f"
@@ -34,6 +40,7 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	MSin := "9be88f33141755dcc1846795217f8cd632559771fbd75fb45033ae0e3adfeefa"
 	SATSin := "dae6d4b1df8df6e1ccb7d90463601475c70c4958ad98c2de07141f8baf77390b"
 	tkSAPPin := "2feeba2461c64d98bd39a71ee1f20e59e7d85b3d99ad6a0e4fc8e29c3d9e8e0a"
+	ivSAPPin := "6bbfa94c0a44c3f416918b26793f913b2b14d54ee306cabde3505516dfb7dd27"
 	tkCommit := "e9c300234adbf690e81334e79d0c82b4e3a76a77d647c8d19df5968dc57248ba" // tkHash
 
 	// authtag params
@@ -42,6 +49,20 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	ecb0 := "a5cd49b7c29ad21fedbcedc01e0f13e8"
 	ecbk := "1c9c7c260c39bcb8dcfa5fbc9330b9fa"
 
+	// TLS_AES_256_GCM_SHA384 data
+	if cipherSuite == TLS_AES_256_GCM_SHA384 {
+		intermediateHashHSopad = "c9dc4a59050490de9ac41a55e524b0aef8d4911eb9bd63f8ee899e08accc492ba2407aa2b31dee1160bd4b8ec40e3cc58032559e5f199bcb47f074af2906e732"
+		dHSin = "e200c89774b2af02021aaf6af98bc38eabd4602505ee4b87656358a351ed460640c462afd98ae1dcdea1544bbbe5b64d"
+		MSin = "470dfdef3dbbde75776c9f94bfa6ab77917a77a83da4cdd42709c67849cc7c385b40074e6de1ad269a95c5b870c08812"
+		SATSin = "9e6e0dc72f3ce34b5e2e4639da6fb04ebad5bf72b4ef122362fd55b53a6057935c07bc96ebec417c6b875ac2405e9449"
+		tkSAPPin = "33b9958f24f08e6e86139876f66fcf7e6ebca54a145ebc6323aae1d7cc4ab3a6bd76a10684ec3f2ddbf2a9b5a6f1c806"
+		ivSAPPin = "df8162a6c0bb8cff001ba912355f759b970e05d902f338955a1e84d460f4391618baad5e8d759fe8dc1efb81fb685c25"
+		tkCommit = "ee0d567fbbd6d53ef92ef363e0b99de9625d91fdcff758c3fed3d126e111bdde"
+		iv = "d4d2d46ec5204db1f05be5ba"
+		ecb0 = "e5bc412e204a4c3f6abfe1c787277dc0"
+		ecbk = "4f649d0edfd5829cd89ae455cfb156b3"
+	}
+
 	// add counter to iv bytes
 	var sb strings.Builder
 	for i := 0; i < len(iv); i++ {
@@ -64,6 +85,8 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	SATSinByteLen := len(byteSlice)
 	byteSlice, _ = hex.DecodeString(tkSAPPin)
 	tkSAPPinByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(ivSAPPin)
+	ivSAPPinByteLen := len(byteSlice)
 	byteSlice, _ = hex.DecodeString(tkCommit)
 	tkCommitByteLen := len(byteSlice)
 
@@ -77,13 +100,16 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	byteSlice, _ = hex.DecodeString(ecbk)
 	ecbkByteLen := len(byteSlice)
 
-	// add padding out of circuit
-	pad := PadSha256(96)
-	dHSinPadded := make([]byte, 32+len(pad))
+	// add padding out of circuit, inner hash follows one block of the hash function
+	pad := PadSha256(uint64(cipherSuite.BlockLen() + dHSinByteLen))
+	if cipherSuite == TLS_AES_256_GCM_SHA384 {
+		pad = PadSha384(uint64(cipherSuite.BlockLen() + dHSinByteLen))
+	}
+	dHSinPadded := make([]byte, dHSinByteLen+len(pad))
 	copy(dHSinPadded, dHSSlice)
-	copy(dHSinPadded[32:], pad)
+	copy(dHSinPadded[dHSinByteLen:], pad)
 	newdHSin := hex.EncodeToString(dHSinPadded)
-	dHSinByteLen += 32
+	dHSinByteLen += len(pad)
 
 	// witness definition kdc
 	intermediateHashHSopadAssign := StrToIntSlice(intermediateHashHSopad, true)
@@ -91,6 +117,7 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	MSinAssign := StrToIntSlice(MSin, true)
 	SATSinAssign := StrToIntSlice(SATSin, true)
 	tkSAPPinAssign := StrToIntSlice(tkSAPPin, true)
+	ivSAPPinAssign := StrToIntSlice(ivSAPPin, true)
 	tkCommitAssign := StrToIntSlice(tkCommit, true)
 
 	// witness definition authtag
@@ -102,11 +129,12 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	// witness values preparation
 	assignment := Tls13SessionCommitWrapper{
 		// kdc params
-		IntermediateHashHSopad: [32]frontend.Variable{},
-		DHSin:                  [64]frontend.Variable{},
-		MSin:                   [32]frontend.Variable{},
-		SATSin:                 [32]frontend.Variable{},
-		TkSAPPin:               [32]frontend.Variable{},
+		IntermediateHashHSopad: make([]frontend.Variable, intermediateHashHSopadByteLen),
+		DHSin:                  make([]frontend.Variable, dHSinByteLen),
+		MSin:                   make([]frontend.Variable, MSinByteLen),
+		SATSin:                 make([]frontend.Variable, SATSinByteLen),
+		TkSAPPin:               make([]frontend.Variable, tkSAPPinByteLen),
+		IvSAPPin:               make([]frontend.Variable, ivSAPPinByteLen),
 		TkCommit:               [32]frontend.Variable{},
 		// authtag params
 		IvCounter: [16]frontend.Variable{},
@@ -131,6 +159,9 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	for i := 0; i < tkSAPPinByteLen; i++ {
 		assignment.TkSAPPin[i] = tkSAPPinAssign[i]
 	}
+	for i := 0; i < ivSAPPinByteLen; i++ {
+		assignment.IvSAPPin[i] = ivSAPPinAssign[i]
+	}
 	for i := 0; i < tkCommitByteLen; i++ {
 		assignment.TkCommit[i] = tkCommitAssign[i]
 	}
@@ -150,7 +181,15 @@ func EvaluateSessionCommit(backend string, compile bool) (map[string]time.Durati
 	}
 
 	// var circuit kdcServerKey
-	var circuit Tls13SessionCommitWrapper
+	circuit := Tls13SessionCommitWrapper{
+		CipherSuite:            cipherSuite,
+		IntermediateHashHSopad: make([]frontend.Variable, intermediateHashHSopadByteLen),
+		DHSin:                  make([]frontend.Variable, dHSinByteLen),
+		MSin:                   make([]frontend.Variable, MSinByteLen),
+		SATSin:                 make([]frontend.Variable, SATSinByteLen),
+		TkSAPPin:               make([]frontend.Variable, tkSAPPinByteLen),
+		IvSAPPin:               make([]frontend.Variable, ivSAPPinByteLen),
+	}
 
 	data, err := ProofWithBackend(backend, compile, &circuit, &assignment, ecc.BN254)
 
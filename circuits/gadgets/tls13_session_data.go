@@ -17,13 +17,17 @@ limitations under the License.
 package gadgets
 
 import (
+	"errors"
+
 	"github.com/consensys/gnark/frontend"
 )
 
 type Tls13SessionDataWrapper struct {
-	Key            [16]frontend.Variable
+	CipherSuite    CipherSuite
+	Key            []frontend.Variable
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable `gnark:",public"`
+	SequenceNumber frontend.Variable     `gnark:",public"`
 	CipherChunks   []frontend.Variable   `gnark:",public"`
 	ChunkIndex     frontend.Variable     `gnark:",public"`
 	Substring      []frontend.Variable   `gnark:",public"`
@@ -33,6 +37,12 @@ type Tls13SessionDataWrapper struct {
 	ValueEnd       int                   `gnark:",public"`
 	Threshold      frontend.Variable     `gnark:",public"`
 	TkCommit       [32]frontend.Variable `gnark:",public"`
+	// key commitment scheme and secret salt
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
+	// optional value commitment, one element each if set
+	Salt            []frontend.Variable
+	ValueCommitment []frontend.Variable `gnark:",public"`
 }
 
 // Define declares the circuit's constraints
@@ -42,7 +52,9 @@ func (circuit *Tls13SessionDataWrapper) Define(api frontend.API) error {
 	session_data := NewTls13SessionData(api)
 
 	// set data
+	session_data.SetCipherSuite(circuit.CipherSuite)
 	session_data.SetCommitParams(circuit.TkCommit)
+	session_data.SetCommitScheme(circuit.CommitScheme, circuit.KeySalt)
 	session_data.SetRecordParams(
 		circuit.Key,
 		circuit.Iv,
@@ -56,20 +68,27 @@ func (circuit *Tls13SessionDataWrapper) Define(api frontend.API) error {
 		circuit.ValueStart,
 		circuit.ValueEnd,
 	)
+	session_data.SetSequenceNumber(circuit.SequenceNumber)
+	if len(circuit.ValueCommitment) > 0 || len(circuit.Salt) > 0 {
+		if len(circuit.ValueCommitment) != 1 || len(circuit.Salt) != 1 {
+			return errors.New("value commitment requires one salt and one commitment")
+		}
+		session_data.SetValueCommitment(circuit.Salt[0], circuit.ValueCommitment[0])
+	}
 
 	// verify everything
-	session_data.Assert()
-
-	return nil
+	return session_data.Assert()
 }
 
 type Tls13SessionData struct {
-	api frontend.API
+	api         frontend.API
+	CipherSuite CipherSuite
 
 	// record params
-	Key            [16]frontend.Variable
+	Key            []frontend.Variable
 	PlainChunks    []frontend.Variable
 	Iv             [12]frontend.Variable // `gnark:",public"`
+	SequenceNumber frontend.Variable     // `gnark:",public"`
 	CipherChunks   []frontend.Variable   // `gnark:",public"`
 	ChunkIndex     frontend.Variable     // `gnark:",public"`
 	Substring      []frontend.Variable   // `gnark:",public"`
@@ -80,14 +99,25 @@ type Tls13SessionData struct {
 	Threshold      frontend.Variable     // `gnark:",public"`
 
 	// commitment params
-	TkCommit [32]frontend.Variable // `gnark:",public"`
+	TkCommit     [32]frontend.Variable // `gnark:",public"`
+	CommitScheme KeyCommitScheme
+	KeySalt      []frontend.Variable
+
+	// value commitment params
+	Salt       frontend.Variable
+	Commitment frontend.Variable // `gnark:",public"`
 }
 
 func NewTls13SessionData(api frontend.API) Tls13SessionData {
 	return Tls13SessionData{api: api}
 }
 
-func (circuit *Tls13SessionData) SetRecordParams(key [16]frontend.Variable, iv [12]frontend.Variable, plainChunks, cipherChunks, substring []frontend.Variable, chunkIndex, threshold frontend.Variable, substringStart, substringEnd, valueStart, valueEnd int) {
+// defaults to TLS_AES_128_GCM_SHA256
+func (circuit *Tls13SessionData) SetCipherSuite(cipherSuite CipherSuite) {
+	circuit.CipherSuite = cipherSuite
+}
+
+func (circuit *Tls13SessionData) SetRecordParams(key []frontend.Variable, iv [12]frontend.Variable, plainChunks, cipherChunks, substring []frontend.Variable, chunkIndex, threshold frontend.Variable, substringStart, substringEnd, valueStart, valueEnd int) {
 	circuit.Key = key
 	circuit.PlainChunks = plainChunks
 	circuit.Iv = iv
@@ -101,19 +131,42 @@ func (circuit *Tls13SessionData) SetRecordParams(key [16]frontend.Variable, iv [
 	circuit.ValueEnd = valueEnd
 }
 
+func (circuit *Tls13SessionData) SetSequenceNumber(sequenceNumber frontend.Variable) {
+	circuit.SequenceNumber = sequenceNumber
+}
+
 func (circuit *Tls13SessionData) SetCommitParams(tkCommit [32]frontend.Variable) {
 	circuit.TkCommit = tkCommit
 }
 
+// optional commitment mimc(value || salt) to the extracted value
+func (circuit *Tls13SessionData) SetValueCommitment(salt, commitment frontend.Variable) {
+	circuit.Salt = salt
+	circuit.Commitment = commitment
+}
+
+// optional key commitment scheme, defaults to the unsalted sha256(tk). salted
+// schemes take a secret 32 byte salt
+func (circuit *Tls13SessionData) SetCommitScheme(scheme KeyCommitScheme, salt []frontend.Variable) {
+	circuit.CommitScheme = scheme
+	circuit.KeySalt = salt
+}
+
 // Define declares the circuit's constraints
-func (circuit *Tls13SessionData) Assert() {
+func (circuit *Tls13SessionData) Assert() error {
+
+	// the key commitment comes from the aes only session commit
+	if err := circuit.CipherSuite.requireAESGCM(); err != nil {
+		return err
+	}
 
 	// commit verification
 
 	// commit function
-	sha := NewSHA256(circuit.api)
-	sha.Write(circuit.Key[:])
-	keyCommit := sha.Sum()
+	keyCommit, err := KeyCommitment(circuit.api, circuit.CommitScheme, circuit.Key, circuit.KeySalt)
+	if err != nil {
+		return err
+	}
 
 	// constraints check
 	for i := 0; i < len(circuit.TkCommit); i++ {
@@ -124,6 +177,7 @@ func (circuit *Tls13SessionData) Assert() {
 
 	// init
 	record := NewTls13Record(circuit.api)
+	record.SetCipherSuite(circuit.CipherSuite)
 
 	// insert data
 	record.SetParams(
@@ -139,7 +193,11 @@ func (circuit *Tls13SessionData) Assert() {
 		circuit.ValueStart,
 		circuit.ValueEnd,
 	)
+	record.SetSequenceNumber(circuit.SequenceNumber)
+	if circuit.Commitment != nil {
+		record.SetValueCommitment(circuit.Salt, circuit.Commitment)
+	}
 
 	// verify
-	record.Assert()
+	return record.Assert()
 }
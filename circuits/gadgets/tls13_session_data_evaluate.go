@@ -18,6 +18,7 @@ package gadgets
 
 import (
 	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -25,13 +26,19 @@ import (
 )
 
 // execution of circuit function of program
-func EvaluateSessionData(backend string, compile bool) (map[string]time.Duration, error) {
+func EvaluateSessionData(backend string, compile bool, cipherSuite CipherSuite) (map[string]time.Duration, error) {
+
+	// evaluation data covers aes gcm suites only
+	if !cipherSuite.IsAESGCM() {
+		return nil, errors.New("unsupported cipher suite " + cipherSuite.String())
+	}
 
 	// record params
 	key := "2872658573f95e87550cb26374e5f667"
 	iv := "a54613bf2801a84ce693d0a0"
 	chipherChunks := "419a031754a4897806533c6020e9130f6088747b9f9a1e1eba4cb0518a6d5692"
 	plainChunks := "302c353631204575726f227d2c227072696365223a2233383030322e32222c22"
+	sequenceNumber := 0
 	chunkIndex := 32
 	substring := "\"price\""
 	substringStart := 13
@@ -43,6 +50,14 @@ func EvaluateSessionData(backend string, compile bool) (map[string]time.Duration
 	// commit params
 	tkCommit := "e9c300234adbf690e81334e79d0c82b4e3a76a77d647c8d19df5968dc57248ba" // tkHash
 
+	// TLS_AES_256_GCM_SHA384 data
+	if cipherSuite == TLS_AES_256_GCM_SHA384 {
+		key = "6c2a89464fc54f44b5f3dd2b8673e92b36a51855981a7c6560e4b51950cadabd"
+		iv = "d4d2d46ec5204db1f05be5ba"
+		chipherChunks = "193397e0881072d9c854862b865146ae049ceef374fe89405d4a9411480e5d1f"
+		tkCommit = "ee0d567fbbd6d53ef92ef363e0b99de9625d91fdcff758c3fed3d126e111bdde"
+	}
+
 	// record to bytes
 	byteSlice, _ := hex.DecodeString(key)
 	keyByteLen := len(byteSlice)
@@ -69,10 +84,11 @@ func EvaluateSessionData(backend string, compile bool) (map[string]time.Duration
 		// commit params
 		TkCommit: [32]frontend.Variable{},
 		// record params
-		Key:            [16]frontend.Variable{},
+		Key:            make([]frontend.Variable, keyByteLen),
 		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
 		Iv:             [12]frontend.Variable{},
 		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+		SequenceNumber: sequenceNumber,
 		ChunkIndex:     chunkIndex,
 		Substring:      make([]frontend.Variable, substringByteLen),
 		SubstringStart: substringStart,
@@ -104,6 +120,8 @@ func EvaluateSessionData(backend string, compile bool) (map[string]time.Duration
 
 	// var circuit kdcServerKey
 	circuit := Tls13SessionDataWrapper{
+		CipherSuite:    cipherSuite,
+		Key:            make([]frontend.Variable, keyByteLen),
 		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
 		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
 		Substring:      make([]frontend.Variable, substringByteLen),
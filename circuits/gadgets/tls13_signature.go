@@ -0,0 +1,316 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+)
+
+// CertificateVerify signature schemes supported by the circuits
+type SignatureScheme int
+
+const (
+	// ecdsa_secp256r1_sha256, 0x0403
+	EcdsaP256Sha256 SignatureScheme = iota
+	// rsa_pss_rsae_sha256 with 2048 bit moduli, 0x0804
+	RsaPss2048Sha256
+)
+
+func ParseSignatureScheme(name string) (SignatureScheme, error) {
+	switch name {
+	case "ecdsa_secp256r1_sha256":
+		return EcdsaP256Sha256, nil
+	case "rsa_pss_rsae_sha256":
+		return RsaPss2048Sha256, nil
+	}
+	return EcdsaP256Sha256, errors.New("unsupported signature scheme " + name)
+}
+
+func (scheme SignatureScheme) String() string {
+	if scheme == RsaPss2048Sha256 {
+		return "rsa_pss_rsae_sha256"
+	}
+	return "ecdsa_secp256r1_sha256"
+}
+
+// public key length in bytes, an uncompressed p256 point or the rsa modulus
+func (scheme SignatureScheme) PublicKeyLen() int {
+	if scheme == RsaPss2048Sha256 {
+		return rsaModulusLen
+	}
+	return 65
+}
+
+// signature length in bytes, r || s or the rsa signature
+func (scheme SignatureScheme) SignatureLen() int {
+	if scheme == RsaPss2048Sha256 {
+		return rsaModulusLen
+	}
+	return 64
+}
+
+const (
+	rsaModulusLen = 256
+	rsaExponent   = 65537
+	// emLen - hLen - 1 for sha256 and 2048 bit moduli
+	pssMaskedDBLen = rsaModulusLen - 32 - 1
+)
+
+// context string of server signatures, rfc 8446 section 4.4.3
+const certificateVerifyContext = "TLS 1.3, server CertificateVerify"
+
+// CertificateVerify evaluation
+type CertificateVerifyWrapper struct {
+	Scheme         SignatureScheme
+	TranscriptHash [32]frontend.Variable `gnark:",public"`
+	PublicKey      []frontend.Variable   `gnark:",public"`
+	Signature      []frontend.Variable   `gnark:",public"`
+	// rsa-pss encoded message, empty for ecdsa
+	EncodedMessage []frontend.Variable
+}
+
+// Define declares the circuit's constraints
+func (circuit *CertificateVerifyWrapper) Define(api frontend.API) error {
+
+	certificateVerify := NewTls13CertificateVerify(api)
+	certificateVerify.SetParams(circuit.Scheme, circuit.TranscriptHash[:], circuit.PublicKey, circuit.Signature, circuit.EncodedMessage)
+
+	return certificateVerify.Assert()
+}
+
+// server CertificateVerify over the transcript hash up to the Certificate
+// message. ecdsa signatures are passed as 32 byte big endian r || s, the der
+// encoding of the handshake message is decoded outside of the circuit
+type Tls13CertificateVerify struct {
+	api            frontend.API
+	Scheme         SignatureScheme
+	TranscriptHash []frontend.Variable // `gnark:",public"`
+	PublicKey      []frontend.Variable // `gnark:",public"`
+	Signature      []frontend.Variable // `gnark:",public"`
+	EncodedMessage []frontend.Variable
+}
+
+func NewTls13CertificateVerify(api frontend.API) Tls13CertificateVerify {
+	return Tls13CertificateVerify{api: api}
+}
+
+func (circuit *Tls13CertificateVerify) SetParams(scheme SignatureScheme, transcriptHash, publicKey, signature, encodedMessage []frontend.Variable) {
+	circuit.Scheme = scheme
+	circuit.TranscriptHash = transcriptHash
+	circuit.PublicKey = publicKey
+	circuit.Signature = signature
+	circuit.EncodedMessage = encodedMessage
+}
+
+// Assert checks the signature of the server over the CertificateVerify content
+func (circuit *Tls13CertificateVerify) Assert() error {
+
+	api := circuit.api
+	scheme := circuit.Scheme
+
+	if len(circuit.TranscriptHash) != 32 || len(circuit.PublicKey) != scheme.PublicKeyLen() || len(circuit.Signature) != scheme.SignatureLen() {
+		return errors.New("invalid CertificateVerify parameter lengths for " + scheme.String())
+	}
+
+	// 64 spaces || context string || 0 || transcript hash
+	var content []frontend.Variable
+	for i := 0; i < 64; i++ {
+		content = append(content, 0x20)
+	}
+	for i := 0; i < len(certificateVerifyContext); i++ {
+		content = append(content, int(certificateVerifyContext[i]))
+	}
+	content = append(content, 0)
+	content = append(content, circuit.TranscriptHash...)
+
+	sha := NewSHA256(api)
+	sha.Write(content)
+	digest := sha.Sum()
+
+	switch scheme {
+	case EcdsaP256Sha256:
+		return VerifyECDSAP256(api, circuit.PublicKey, digest[:], circuit.Signature[:32], circuit.Signature[32:])
+	case RsaPss2048Sha256:
+		return VerifyRSAPSS2048(api, circuit.PublicKey, circuit.Signature, circuit.EncodedMessage, digest[:])
+	}
+
+	return errors.New("unsupported signature scheme")
+}
+
+// VerifyECDSAP256 checks an ecdsa signature over a sha256 digest, the public
+// key is an uncompressed point and r, s are 32 byte big endian
+func VerifyECDSAP256(api frontend.API, publicKey, digest, r, s []frontend.Variable) error {
+
+	curve, err := sw_emulated.New[emparams.P256Fp, emparams.P256Fr](api, sw_emulated.GetP256Params())
+	if err != nil {
+		return err
+	}
+	baseField, err := emulated.NewField[emparams.P256Fp](api)
+	if err != nil {
+		return err
+	}
+	scalarField, err := emulated.NewField[emparams.P256Fr](api)
+	if err != nil {
+		return err
+	}
+
+	// uncompressed point 0x04 || x || y
+	api.AssertIsEqual(publicKey[0], 4)
+	point := sw_emulated.AffinePoint[emparams.P256Fp]{
+		X: *baseField.FromBits(bytesToBitsBE(api, publicKey[1:33])...),
+		Y: *baseField.FromBits(bytesToBitsBE(api, publicKey[33:65])...),
+	}
+	curve.AssertIsOnCurve(&point)
+
+	// the digest is reduced modulo the group order
+	msg := scalarField.FromBits(bytesToBitsBE(api, digest)...)
+	sig := ecdsa.Signature[emparams.P256Fr]{
+		R: *scalarField.FromBits(bytesToBitsBE(api, r)...),
+		S: *scalarField.FromBits(bytesToBitsBE(api, s)...),
+	}
+
+	pk := ecdsa.PublicKey[emparams.P256Fp, emparams.P256Fr](point)
+	pk.Verify(api, sw_emulated.GetP256Params(), msg, &sig)
+
+	return nil
+}
+
+// VerifyRSAPSS2048 checks an rsa-pss signature with sha256, mgf1 and 32 byte
+// salts over a sha256 digest. the encoded message is a witness which must
+// equal signature^65537 modulo the big endian modulus, it is below 2^2047 and
+// therefore the unique representative
+func VerifyRSAPSS2048(api frontend.API, modulus, signature, encodedMessage, digest []frontend.Variable) error {
+
+	if len(modulus) != rsaModulusLen || len(signature) != rsaModulusLen || len(encodedMessage) != rsaModulusLen {
+		return errors.New("rsa-pss verification requires 2048 bit moduli")
+	}
+
+	field, err := emulated.NewField[emparams.Mod1e4096](api)
+	if err != nil {
+		return err
+	}
+
+	// modulus of 2048 bits
+	modulusBits := bytesToBitsBE(api, modulus)
+	api.AssertIsEqual(modulusBits[8*rsaModulusLen-1], 1)
+	n := field.FromBits(modulusBits...)
+
+	// em = s^e mod n with e = 2^16 + 1
+	emBits := bytesToBitsBE(api, encodedMessage)
+	api.AssertIsEqual(emBits[8*rsaModulusLen-1], 0)
+	sig := field.FromBits(bytesToBitsBE(api, signature)...)
+	acc := sig
+	for i := 0; i < 16; i++ {
+		acc = field.ModMul(acc, acc, n)
+	}
+	acc = field.ModMul(acc, sig, n)
+	field.ModAssertIsEqual(acc, field.FromBits(emBits...), n)
+
+	// emsa-pss decoding, rfc 8017 section 9.1.2
+	api.AssertIsEqual(encodedMessage[rsaModulusLen-1], 0xbc)
+	maskedDB := encodedMessage[:pssMaskedDBLen]
+	h := encodedMessage[pssMaskedDBLen : rsaModulusLen-1]
+
+	// mgf1 mask of the db length
+	var dbMask []frontend.Variable
+	for counter := 0; len(dbMask) < pssMaskedDBLen; counter++ {
+		sha := NewSHA256(api)
+		sha.Write(append(append([]frontend.Variable{}, h...), 0, 0, 0, counter))
+		sum := sha.Sum()
+		dbMask = append(dbMask, sum[:]...)
+	}
+
+	db := make([]frontend.Variable, pssMaskedDBLen)
+	for i := 0; i < pssMaskedDBLen; i++ {
+		db[i] = VariableXor(api, maskedDB[i], dbMask[i], 8)
+	}
+
+	// the leftmost bit of db is cleared, followed by zero padding and 0x01
+	dbBits := api.ToBinary(db[0], 8)
+	db[0] = api.FromBinary(append(dbBits[:7], 0)...)
+	saltStart := pssMaskedDBLen - 32
+	for i := 0; i < saltStart-1; i++ {
+		api.AssertIsEqual(db[i], 0)
+	}
+	api.AssertIsEqual(db[saltStart-1], 1)
+
+	// h = sha256(0^8 || digest || salt)
+	var m []frontend.Variable
+	for i := 0; i < 8; i++ {
+		m = append(m, 0)
+	}
+	m = append(m, digest...)
+	m = append(m, db[saltStart:]...)
+	sha := NewSHA256(api)
+	sha.Write(m)
+	hPrime := sha.Sum()
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(hPrime[i], h[i])
+	}
+
+	return nil
+}
+
+// CertificateVerifyDigest computes the signed digest out of circuit
+func CertificateVerifyDigest(transcriptHash []byte) []byte {
+	content := make([]byte, 64)
+	for i := range content {
+		content[i] = 0x20
+	}
+	content = append(content, certificateVerifyContext...)
+	content = append(content, 0)
+	content = append(content, transcriptHash...)
+	digest := sha256.Sum256(content)
+	return digest[:]
+}
+
+// ECDSASignature converts a der encoded ecdsa signature into r || s
+func ECDSASignature(der []byte) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 || sig.R.BitLen() > 256 || sig.S.BitLen() > 256 {
+		return nil, errors.New("invalid ecdsa signature encoding")
+	}
+	out := make([]byte, 64)
+	sig.R.FillBytes(out[:32])
+	sig.S.FillBytes(out[32:])
+	return out, nil
+}
+
+// RSAEncodedMessage recovers the encoded message signature^e mod n, the
+// witness of VerifyRSAPSS2048
+func RSAEncodedMessage(publicKey *rsa.PublicKey, signature []byte) ([]byte, error) {
+	if publicKey.N.BitLen() != 8*rsaModulusLen || publicKey.E != rsaExponent {
+		return nil, errors.New("rsa-pss verification requires 2048 bit moduli and e = 65537")
+	}
+	s := new(big.Int).SetBytes(signature)
+	em := new(big.Int).Exp(s, big.NewInt(rsaExponent), publicKey.N)
+	return em.FillBytes(make([]byte, rsaModulusLen)), nil
+}
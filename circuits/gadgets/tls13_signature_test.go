@@ -0,0 +1,103 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestCertificateVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	transcriptHash := sha256.Sum256([]byte("ClientHello ... Certificate"))
+	digest := CertificateVerifyDigest(transcriptHash[:])
+
+	// ecdsa_secp256r1_sha256
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaSignature, err := ECDSASignature(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaPublicKey := elliptic.Marshal(elliptic.P256(), ecdsaKey.X, ecdsaKey.Y)
+
+	// rsa_pss_rsae_sha256
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSignature, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedMessage, err := RSAEncodedMessage(&rsaKey.PublicKey, rsaSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		scheme         SignatureScheme
+		publicKey      []byte
+		signature      []byte
+		encodedMessage []byte
+	}{
+		{EcdsaP256Sha256, ecdsaPublicKey, ecdsaSignature, nil},
+		{RsaPss2048Sha256, rsaKey.N.FillBytes(make([]byte, 256)), rsaSignature, encodedMessage},
+	} {
+		newCircuit := func() *CertificateVerifyWrapper {
+			return &CertificateVerifyWrapper{
+				Scheme:         c.scheme,
+				PublicKey:      make([]frontend.Variable, c.scheme.PublicKeyLen()),
+				Signature:      make([]frontend.Variable, c.scheme.SignatureLen()),
+				EncodedMessage: make([]frontend.Variable, len(c.encodedMessage)),
+			}
+		}
+		newAssignment := func(transcriptHash []byte) *CertificateVerifyWrapper {
+			assignment := newCircuit()
+			for i := 0; i < 32; i++ {
+				assignment.TranscriptHash[i] = transcriptHash[i]
+			}
+			assignment.PublicKey = toVariables(c.publicKey)
+			assignment.Signature = toVariables(c.signature)
+			assignment.EncodedMessage = toVariables(c.encodedMessage)
+			return assignment
+		}
+
+		assert.SolvingSucceeded(newCircuit(), newAssignment(transcriptHash[:]), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// the signature does not cover a different transcript
+		other := sha256.Sum256([]byte("ClientHello ... other Certificate"))
+		assert.SolvingFailed(newCircuit(), newAssignment(other[:]), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
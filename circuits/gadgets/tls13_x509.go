@@ -0,0 +1,427 @@
+/*
+Copyright 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// der SubjectPublicKeyInfo prefixes up to the key bytes
+var (
+	// ecPublicKey, prime256v1, bit string of an uncompressed point
+	spkiP256Header = []byte{
+		0x30, 0x59, 0x30, 0x13, 0x06, 0x07, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x02, 0x01,
+		0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07, 0x03, 0x42, 0x00,
+	}
+	// rsaEncryption, bit string of RSAPublicKey with a 2048 bit modulus
+	spkiRSA2048Header = []byte{
+		0x30, 0x82, 0x01, 0x22, 0x30, 0x0d, 0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7,
+		0x0d, 0x01, 0x01, 0x01, 0x05, 0x00, 0x03, 0x82, 0x01, 0x0f, 0x00, 0x30, 0x82,
+		0x01, 0x0a, 0x02, 0x82, 0x01, 0x01, 0x00,
+	}
+	// public exponent 65537 after the modulus
+	spkiRSAExponent = []byte{0x02, 0x03, 0x01, 0x00, 0x01}
+
+	// extension id of subjectAltName and attribute type of commonName
+	oidSubjectAltName = []byte{0x06, 0x03, 0x55, 0x1d, 0x11}
+	oidCommonName     = []byte{0x06, 0x03, 0x55, 0x04, 0x03}
+)
+
+// GeneralName tag of dNSName entries
+const sanDNSNameTag = 0x82
+
+// bounds of the der walks over subject names, extensions and GeneralNames
+const (
+	x509MaxRdns       = 16
+	x509MaxExtensions = 24
+	x509MaxNames      = 64
+)
+
+// SubjectPublicKeyInfo length of the scheme
+func (scheme SignatureScheme) spkiLen() int {
+	if scheme == RsaPss2048Sha256 {
+		return len(spkiRSA2048Header) + rsaModulusLen + len(spkiRSAExponent)
+	}
+	return len(spkiP256Header) + 65
+}
+
+// certificate evaluation
+type X509Wrapper struct {
+	Scheme          SignatureScheme
+	Certificate     []frontend.Variable
+	CertificateHash [32]frontend.Variable `gnark:",public"`
+	SanStart        frontend.Variable
+	NameStart       frontend.Variable
+	CommonName      bool
+	PublicKey       []frontend.Variable `gnark:",public"`
+	Domain          []frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *X509Wrapper) Define(api frontend.API) error {
+
+	certificate := NewTls13Certificate(api)
+	certificate.SetParams(circuit.Scheme, circuit.Certificate, circuit.CertificateHash[:], circuit.PublicKey, circuit.Domain)
+	if circuit.CommonName {
+		// no subjectAltName offset
+		api.AssertIsEqual(circuit.SanStart, 0)
+		certificate.SetCommonName(circuit.NameStart)
+	} else {
+		certificate.SetSubjectAltName(circuit.SanStart, circuit.NameStart)
+	}
+
+	return certificate.Assert()
+}
+
+// binding of a server key and a dns name to a der v3 certificate. the gadget
+// walks the tbsCertificate fields to the SubjectPublicKeyInfo, and the name
+// offsets are witnesses that must select an element of the walked subject
+// rdns or of the extensions and their GeneralNames, so bytes inside other
+// fields cannot pose as names. issuer and subject unique ids are not
+// supported. the sha256 hash of the certificate identifies it to the
+// verifier. certificates must be between 256 bytes and 64 kB
+type Tls13Certificate struct {
+	api             frontend.API
+	Scheme          SignatureScheme
+	Certificate     []frontend.Variable
+	CertificateHash []frontend.Variable // `gnark:",public"`
+	PublicKey       []frontend.Variable // `gnark:",public"`
+	Domain          []frontend.Variable // `gnark:",public"`
+	SanStart        frontend.Variable
+	NameStart       frontend.Variable
+	CommonName      bool
+}
+
+func NewTls13Certificate(api frontend.API) Tls13Certificate {
+	return Tls13Certificate{api: api}
+}
+
+// the public key has the layout of CertificateVerify keys of the scheme
+func (circuit *Tls13Certificate) SetParams(scheme SignatureScheme, certificate, certificateHash, publicKey, domain []frontend.Variable) {
+	circuit.Scheme = scheme
+	circuit.Certificate = certificate
+	circuit.CertificateHash = certificateHash
+	circuit.PublicKey = publicKey
+	circuit.Domain = domain
+}
+
+// domain matches the dNSName at nameStart of the subjectAltName extension
+// whose oid starts at sanStart
+func (circuit *Tls13Certificate) SetSubjectAltName(sanStart, nameStart frontend.Variable) {
+	circuit.SanStart = sanStart
+	circuit.NameStart = nameStart
+	circuit.CommonName = false
+}
+
+// domain matches a subject commonName at nameStart, for certificates without
+// subjectAltName
+func (circuit *Tls13Certificate) SetCommonName(nameStart frontend.Variable) {
+	circuit.NameStart = nameStart
+	circuit.CommonName = true
+}
+
+// Assert checks the public key and domain against the certificate
+func (circuit *Tls13Certificate) Assert() error {
+
+	api := circuit.api
+	scheme := circuit.Scheme
+	cert := circuit.Certificate
+	domainLen := len(circuit.Domain)
+
+	if len(cert) < 256 || len(cert) > 0xffff+4 {
+		return errors.New("certificate length out of range")
+	}
+	if len(circuit.CertificateHash) != 32 {
+		return errors.New("certificate hash must have 32 bytes")
+	}
+	if len(circuit.PublicKey) != scheme.PublicKeyLen() {
+		return errors.New("invalid public key length for " + scheme.String())
+	}
+	if domainLen == 0 || domainLen > 127 {
+		return errors.New("domain must have between 1 and 127 bytes")
+	}
+
+	maxLen := scheme.spkiLen()
+	if domainLen+len(oidCommonName)+2 > maxLen {
+		maxLen = domainLen + len(oidCommonName) + 2
+	}
+	extractor := NewExtractor(api, cert, maxLen)
+
+	// Certificate sequence with a two byte length and the tbsCertificate
+	api.AssertIsEqual(cert[0], 0x30)
+	api.AssertIsEqual(cert[1], 0x82)
+	api.AssertIsEqual(api.Add(api.Mul(cert[2], 256), cert[3]), len(cert)-4)
+	api.AssertIsEqual(cert[4], 0x30)
+	tbsStart, tbsLen := derLength(api, &extractor, 5)
+	tbsEnd := api.Add(tbsStart, tbsLen)
+
+	// sha256 of the der certificate
+	sha := NewSHA256(api)
+	sha.Write(cert)
+	hash := sha.Sum()
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(hash[i], circuit.CertificateHash[i])
+	}
+
+	// version, serialNumber, signature, issuer, validity and subject precede
+	// the SubjectPublicKeyInfo, the subject is the last field walked
+	offset := tbsStart
+	var subjectStart frontend.Variable
+	for _, tag := range []int{0xa0, 0x02, 0x30, 0x30, 0x30, 0x30} {
+		t := extractor.Substring(offset, 1)
+		api.AssertIsEqual(t[0], tag)
+		contentStart, length := derLength(api, &extractor, api.Add(offset, 1))
+		subjectStart = contentStart
+		offset = api.Add(contentStart, length)
+	}
+	spkiStart := offset
+	spkiEnd := api.Add(spkiStart, scheme.spkiLen())
+	api.AssertIsLessOrEqual(spkiEnd, tbsEnd)
+
+	// SubjectPublicKeyInfo
+	spki := extractor.Substring(spkiStart, scheme.spkiLen())
+	header := spkiP256Header
+	if scheme == RsaPss2048Sha256 {
+		header = spkiRSA2048Header
+		trailer := spki[len(header)+rsaModulusLen:]
+		for i := 0; i < len(spkiRSAExponent); i++ {
+			api.AssertIsEqual(trailer[i], spkiRSAExponent[i])
+		}
+	}
+	for i := 0; i < len(header); i++ {
+		api.AssertIsEqual(spki[i], header[i])
+	}
+	key := spki[len(header) : len(header)+len(circuit.PublicKey)]
+	SubstringMatch(api, circuit.PublicKey, key, 0, len(key))
+
+	// dns name
+	if circuit.CommonName {
+		// SET of each RelativeDistinguishedName holds a SEQUENCE whose content
+		// starts with the attribute type, the name is the first attribute of an rdn
+		rdns, contents, present := derElements(api, &extractor, subjectStart, spkiStart, x509MaxRdns)
+		derTags(api, &extractor, rdns, present, 0x31)
+		derTags(api, &extractor, contents, present, 0x30)
+		attributes := make([]frontend.Variable, len(contents))
+		for i := range contents {
+			attributes[i], _ = derLength(api, &extractor, api.Add(contents[i], 1))
+		}
+		derMember(api, attributes, present, api.Sub(circuit.NameStart, len(oidCommonName)+2))
+
+		// oid || string tag || length || name
+		name := extractor.Substring(api.Sub(circuit.NameStart, len(oidCommonName)+2), len(oidCommonName)+2+domainLen)
+		for i := 0; i < len(oidCommonName); i++ {
+			api.AssertIsEqual(name[i], oidCommonName[i])
+		}
+		tag := name[len(oidCommonName)]
+		api.AssertIsEqual(api.Mul(api.Sub(tag, 0x0c), api.Sub(tag, 0x13), api.Sub(tag, 0x16)), 0)
+		api.AssertIsEqual(name[len(oidCommonName)+1], domainLen)
+		SubstringMatch(api, circuit.Domain, name[len(oidCommonName)+2:], 0, domainLen)
+		return nil
+	}
+
+	// extensions [3] is the last tbsCertificate field and holds the SEQUENCE
+	// OF Extension, the subjectAltName oid is the extnID of one of them
+	explicit := extractor.Substring(spkiEnd, 1)
+	api.AssertIsEqual(explicit[0], 0xa3)
+	seqStart, _ := derLength(api, &extractor, api.Add(spkiEnd, 1))
+	seq := extractor.Substring(seqStart, 1)
+	api.AssertIsEqual(seq[0], 0x30)
+	extensionsStart, extensionsLen := derLength(api, &extractor, api.Add(seqStart, 1))
+	api.AssertIsEqual(api.Add(extensionsStart, extensionsLen), tbsEnd)
+	extensions, contents, present := derElements(api, &extractor, extensionsStart, tbsEnd, x509MaxExtensions)
+	derTags(api, &extractor, extensions, present, 0x30)
+	derMember(api, contents, present, circuit.SanStart)
+
+	// extnID || critical? || OCTET STRING || SEQUENCE OF GeneralName
+	ext := extractor.Substring(circuit.SanStart, len(oidSubjectAltName)+3)
+	for i := 0; i < len(oidSubjectAltName); i++ {
+		api.AssertIsEqual(ext[i], oidSubjectAltName[i])
+	}
+	critical := api.IsZero(api.Sub(ext[len(oidSubjectAltName)], 0x01))
+	api.AssertIsEqual(api.Select(critical, api.Add(api.Mul(ext[len(oidSubjectAltName)+1], 256), ext[len(oidSubjectAltName)+2]), 0x01ff), 0x01ff)
+	octetStart := api.Add(circuit.SanStart, len(oidSubjectAltName), api.Mul(critical, 3))
+	octet := extractor.Substring(octetStart, 1)
+	api.AssertIsEqual(octet[0], 0x04)
+	seqStart, _ = derLength(api, &extractor, api.Add(octetStart, 1))
+	seq = extractor.Substring(seqStart, 1)
+	api.AssertIsEqual(seq[0], 0x30)
+	namesStart, namesLen := derLength(api, &extractor, api.Add(seqStart, 1))
+
+	// dNSName entry of the sequence
+	names, _, present := derElements(api, &extractor, namesStart, api.Add(namesStart, namesLen), x509MaxNames)
+	derMember(api, names, present, api.Sub(circuit.NameStart, 2))
+	entry := extractor.Substring(api.Sub(circuit.NameStart, 2), domainLen+2)
+	api.AssertIsEqual(entry[0], sanDNSNameTag)
+	api.AssertIsEqual(entry[1], domainLen)
+	SubstringMatch(api, circuit.Domain, entry[2:], 0, domainLen)
+
+	return nil
+}
+
+// derLength decodes the short, one and two byte length forms at start and
+// returns the content offset and length
+func derLength(api frontend.API, extractor *Extractor, start frontend.Variable) (frontend.Variable, frontend.Variable) {
+
+	l := extractor.Substring(start, 3)
+	short := api.Sub(1, api.ToBinary(l[0], 8)[7])
+	long1 := api.IsZero(api.Sub(l[0], 0x81))
+	long2 := api.IsZero(api.Sub(l[0], 0x82))
+	api.AssertIsEqual(api.Add(short, long1, long2), 1)
+
+	length := api.Add(api.Mul(short, l[0]), api.Mul(long1, l[1]), api.Mul(long2, api.Add(api.Mul(l[1], 256), l[2])))
+	contentStart := api.Add(start, 1, long1, api.Mul(long2, 2))
+
+	return contentStart, length
+}
+
+// derElements walks the consecutive der elements from start to end, at most
+// maxElements of them, and returns their offsets, content offsets and
+// whether they are part of the walk. the walk must end exactly at end and
+// afterwards stays there, the element that follows is well formed der in
+// the certificates walked
+func derElements(api frontend.API, extractor *Extractor, start, end frontend.Variable, maxElements int) ([]frontend.Variable, []frontend.Variable, []frontend.Variable) {
+
+	offsets := make([]frontend.Variable, maxElements)
+	contents := make([]frontend.Variable, maxElements)
+	present := make([]frontend.Variable, maxElements)
+
+	offset := start
+	for i := 0; i < maxElements; i++ {
+		done := api.IsZero(api.Sub(offset, end))
+		contentStart, length := derLength(api, extractor, api.Add(offset, 1))
+		offsets[i] = offset
+		contents[i] = contentStart
+		present[i] = api.Sub(1, done)
+		offset = api.Select(done, offset, api.Add(contentStart, length))
+	}
+	api.AssertIsEqual(offset, end)
+
+	return offsets, contents, present
+}
+
+// derTags checks the tag of the present elements
+func derTags(api frontend.API, extractor *Extractor, offsets, present []frontend.Variable, tag int) {
+	for i := range offsets {
+		t := extractor.Substring(offsets[i], 1)
+		api.AssertIsEqual(api.Mul(present[i], api.Sub(t[0], tag)), 0)
+	}
+}
+
+// derMember checks that offset is one of the present offsets, which are
+// distinct
+func derMember(api frontend.API, offsets, present []frontend.Variable, offset frontend.Variable) {
+	found := frontend.Variable(0)
+	for i := range offsets {
+		found = api.Add(found, api.Mul(present[i], api.IsZero(api.Sub(offsets[i], offset))))
+	}
+	api.AssertIsEqual(found, 1)
+}
+
+// CertificateOffsets locates the subjectAltName extension and the dNSName of
+// domain in a der certificate out of circuit
+func CertificateOffsets(der []byte, domain string) (sanStart, nameStart int, err error) {
+
+	if _, err := x509.ParseCertificate(der); err != nil {
+		return 0, 0, err
+	}
+
+	// Certificate, tbsCertificate and its last field, the extensions
+	_, offsets, err := derChildren(der, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields, offsets, err := derChildren(der, offsets[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	last := len(fields) - 1
+	if fields[last].Class != asn1.ClassContextSpecific || fields[last].Tag != 3 {
+		return 0, 0, errors.New("certificate without extensions")
+	}
+	_, offsets, err = derChildren(der, offsets[last])
+	if err != nil {
+		return 0, 0, err
+	}
+	extensions, offsets, err := derChildren(der, offsets[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i, extension := range extensions {
+		if !bytes.HasPrefix(extension.Bytes, oidSubjectAltName) {
+			continue
+		}
+		sanStart = offsets[i] + len(extension.FullBytes) - len(extension.Bytes)
+
+		// extnValue OCTET STRING holding the SEQUENCE OF GeneralName
+		values, valueOffsets, err := derChildren(der, offsets[i])
+		if err != nil {
+			return 0, 0, err
+		}
+		_, valueOffsets, err = derChildren(der, valueOffsets[len(values)-1])
+		if err != nil {
+			return 0, 0, err
+		}
+		names, nameOffsets, err := derChildren(der, valueOffsets[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		for j, name := range names {
+			if name.Class == asn1.ClassContextSpecific && name.Tag == sanDNSNameTag&0x1f && string(name.Bytes) == domain {
+				return sanStart, nameOffsets[j] + len(name.FullBytes) - len(name.Bytes), nil
+			}
+		}
+		return 0, 0, errors.New("domain not in subjectAltName " + domain)
+	}
+
+	return 0, 0, errors.New("certificate without subjectAltName")
+}
+
+// derChildren parses the der element at offset and returns the elements of
+// its content with their offsets
+func derChildren(der []byte, offset int) ([]asn1.RawValue, []int, error) {
+
+	var element asn1.RawValue
+	if _, err := asn1.Unmarshal(der[offset:], &element); err != nil {
+		return nil, nil, err
+	}
+
+	var children []asn1.RawValue
+	var offsets []int
+	offset += len(element.FullBytes) - len(element.Bytes)
+	for rest := element.Bytes; len(rest) > 0; {
+		var child asn1.RawValue
+		next, err := asn1.Unmarshal(rest, &child)
+		if err != nil {
+			return nil, nil, err
+		}
+		children = append(children, child)
+		offsets = append(offsets, offset)
+		offset += len(child.FullBytes)
+		rest = next
+	}
+	if len(children) == 0 {
+		return nil, nil, errors.New("empty der element")
+	}
+
+	return children, offsets, nil
+}
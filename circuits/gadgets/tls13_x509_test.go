@@ -0,0 +1,197 @@
+/*
+Copyright © 2023 Jan Lauinger
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gadgets
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// self signed certificate for a subject commonName and dns names
+func selfSignedCertificate(t *testing.T, key interface{}, publicKey interface{}, commonName string, names ...string) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"example"}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     names,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestCertificate(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	domain := "api.example.com"
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		scheme    SignatureScheme
+		der       []byte
+		publicKey []byte
+	}{
+		{EcdsaP256Sha256, selfSignedCertificate(t, ecdsaKey, &ecdsaKey.PublicKey, domain, "www.example.com", domain), elliptic.Marshal(elliptic.P256(), ecdsaKey.X, ecdsaKey.Y)},
+		{RsaPss2048Sha256, selfSignedCertificate(t, rsaKey, &rsaKey.PublicKey, domain, "www.example.com", domain), rsaKey.N.FillBytes(make([]byte, 256))},
+	} {
+		sanStart, nameStart, err := CertificateOffsets(c.der, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(c.der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spkiStart := bytes.Index(c.der, cert.RawSubjectPublicKeyInfo)
+
+		newCircuit := func(commonName bool) *X509Wrapper {
+			return &X509Wrapper{
+				Scheme:      c.scheme,
+				Certificate: make([]frontend.Variable, len(c.der)),
+				CommonName:  commonName,
+				PublicKey:   make([]frontend.Variable, c.scheme.PublicKeyLen()),
+				Domain:      make([]frontend.Variable, len(domain)),
+			}
+		}
+		newAssignment := func(domain string, sanStart, nameStart int) *X509Wrapper {
+			assignment := newCircuit(false)
+			assignment.Certificate = toVariables(c.der)
+			hash := sha256.Sum256(c.der)
+			for i := 0; i < 32; i++ {
+				assignment.CertificateHash[i] = hash[i]
+			}
+			assignment.SanStart = sanStart
+			assignment.NameStart = nameStart
+			assignment.PublicKey = toVariables(c.publicKey)
+			assignment.Domain = toVariables([]byte(domain))
+			return assignment
+		}
+
+		// subjectAltName
+		assert.SolvingSucceeded(newCircuit(false), newAssignment(domain, sanStart, nameStart), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		assert.SolvingFailed(newCircuit(false), newAssignment("api.example.org", sanStart, nameStart), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// a different key is not bound to the certificate
+		assignment := newAssignment(domain, sanStart, nameStart)
+		assignment.PublicKey[len(c.publicKey)-1] = c.publicKey[len(c.publicKey)-1] ^ 1
+		assert.SolvingFailed(newCircuit(false), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// the hash of another certificate must fail
+		assignment = newAssignment(domain, sanStart, nameStart)
+		hash := sha256.Sum256(c.der)
+		assignment.CertificateHash[0] = hash[0] ^ 1
+		assert.SolvingFailed(newCircuit(false), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// subject commonName, printable strings are the last name before the public key
+		cn := append(append([]byte{}, oidCommonName...), 0x13, byte(len(domain)))
+		cnStart := bytes.LastIndex(c.der[:spkiStart], append(cn, domain...)) + len(cn)
+		assignment = newAssignment(domain, 0, cnStart)
+		assert.SolvingSucceeded(newCircuit(true), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+		// the subjectAltName entry is not a commonName
+		assignment = newAssignment(domain, 0, nameStart)
+		assert.SolvingFailed(newCircuit(true), assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestCertificatePlantedNames(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	domain := "api.example.com"
+
+	// a subjectAltName extension and a commonName attribute for domain inside
+	// the modulus of a key certified for www.example.org only
+	san := append(append([]byte{}, oidSubjectAltName...), 0x04, byte(len(domain)+4), 0x30, byte(len(domain)+2), sanDNSNameTag, byte(len(domain)))
+	san = append(san, domain...)
+	cn := append(append([]byte{}, oidCommonName...), 0x13, byte(len(domain)))
+	cn = append(cn, domain...)
+	modulus := make([]byte, rsaModulusLen)
+	if _, err := rand.Read(modulus); err != nil {
+		t.Fatal(err)
+	}
+	modulus[0] |= 0x80
+	modulus[len(modulus)-1] |= 0x01
+	copy(modulus[8:], san)
+	copy(modulus[64:], cn)
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: 65537}
+
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := selfSignedCertificate(t, signer, publicKey, "www.example.org", "www.example.org")
+	if _, _, err := CertificateOffsets(der, domain); err == nil {
+		t.Fatal("planted subjectAltName located")
+	}
+	sanStart := bytes.Index(der, san)
+	cnStart := bytes.Index(der, cn)
+	if sanStart < 0 || cnStart < 0 {
+		t.Fatal("planted names not in certificate")
+	}
+
+	newCircuit := func(commonName bool) *X509Wrapper {
+		return &X509Wrapper{
+			Scheme:      RsaPss2048Sha256,
+			Certificate: make([]frontend.Variable, len(der)),
+			CommonName:  commonName,
+			PublicKey:   make([]frontend.Variable, RsaPss2048Sha256.PublicKeyLen()),
+			Domain:      make([]frontend.Variable, len(domain)),
+		}
+	}
+	newAssignment := func(commonName bool, sanStart, nameStart int) *X509Wrapper {
+		assignment := newCircuit(commonName)
+		assignment.Certificate = toVariables(der)
+		hash := sha256.Sum256(der)
+		for i := 0; i < 32; i++ {
+			assignment.CertificateHash[i] = hash[i]
+		}
+		assignment.SanStart = sanStart
+		assignment.NameStart = nameStart
+		assignment.PublicKey = toVariables(modulus)
+		assignment.Domain = toVariables([]byte(domain))
+		return assignment
+	}
+
+	// the names parse at their offsets but are not elements of the extensions or the subject
+	assert.SolvingFailed(newCircuit(false), newAssignment(false, sanStart, sanStart+len(san)-len(domain)), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(newCircuit(true), newAssignment(true, 0, cnStart+len(cn)-len(domain)), test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
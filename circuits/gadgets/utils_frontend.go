@@ -57,6 +57,33 @@ func OpadConcat(api frontend.API, inp1 [32]frontend.Variable, inp2 [32]frontend.
 	return dHSopadConcatMSin
 }
 
+// inp1 zero padded to blockSize, xor opad and concatenates with inp2
+func OpadConcatN(api frontend.API, inp1, inp2 []frontend.Variable, blockSize int) []frontend.Variable {
+	out := make([]frontend.Variable, blockSize+len(inp2))
+	for i := 0; i < blockSize; i++ {
+		if i < len(inp1) {
+			out[i] = VariableXor(api, inp1[i], frontend.Variable(0x5c), 8)
+		} else {
+			out[i] = frontend.Variable(0x5c)
+		}
+	}
+	copy(out[blockSize:], inp2)
+	return out
+}
+
+// key zero padded to blockSize and xored with pad byte, e.g. 0x36 ipad or 0x5c opad
+func PadXor(api frontend.API, key []frontend.Variable, pad byte, blockSize int) []frontend.Variable {
+	out := make([]frontend.Variable, blockSize)
+	for i := 0; i < blockSize; i++ {
+		if i < len(key) {
+			out[i] = VariableXor(api, key[i], frontend.Variable(pad), 8)
+		} else {
+			out[i] = frontend.Variable(pad)
+		}
+	}
+	return out
+}
+
 // adjustable bitwise xor operation on frontend.Variables
 func VariableXor(api frontend.API, a frontend.Variable, b frontend.Variable, size int) frontend.Variable {
 	bitsA := api.ToBinary(a, size)
@@ -30,6 +30,24 @@ func PadSha256(len uint64) []byte {
 	return padlen
 }
 
+// non-gnark padding function, 128 bit length field
+func PadSha384(len uint64) []byte {
+	var tmp [128 + 16]byte // padding + length buffer
+	tmp[0] = 0x80
+	var t uint64
+	if len%128 < 112 {
+		t = 112 - len%128
+	} else {
+		t = 128 + 112 - len%128
+	}
+
+	// Length in bits, upper 64 bits are zero.
+	len <<= 3
+	padlen := tmp[:t+16]
+	binary.BigEndian.PutUint64(padlen[t+8:], len)
+	return padlen
+}
+
 // non-gnark str to int conversion
 func StrToIntSlice(inputData string, hexRepresentation bool) []int {
 
@@ -177,6 +177,47 @@ func EvaluateSha256(backend string, compile bool, in, hash string) (map[string]t
 	return data, err
 }
 
+// execution of circuit function of program
+func EvaluateSha384(backend string, compile bool, in, hash string) (map[string]time.Duration, error) {
+
+	log.Debug().Msg("EvaluateSha384")
+
+	// input to bytes
+	byteSlice, _ := hex.DecodeString(in)
+	inByteLen := len(byteSlice)
+
+	log.Debug().Str("length", strconv.Itoa(inByteLen)).Msg("sha384 input size")
+
+	byteSlice, _ = hex.DecodeString(hash)
+	hashByteLen := len(byteSlice)
+
+	// witness definition
+	inAssign := StrToIntSlice(in, true)
+	hashAssign := StrToIntSlice(hash, true)
+
+	// witness values preparation
+	assignment := Sha384Wrapper{
+		In:   make([]frontend.Variable, inByteLen),
+		Hash: [48]frontend.Variable{},
+	}
+
+	// assign
+	for i := 0; i < inByteLen; i++ {
+		assignment.In[i] = inAssign[i]
+	}
+	for i := 0; i < hashByteLen; i++ {
+		assignment.Hash[i] = hashAssign[i]
+	}
+
+	circuit := Sha384Wrapper{
+		In: make([]frontend.Variable, inByteLen),
+	}
+
+	data, err := ProofWithBackend(backend, compile, &circuit, &assignment, ecc.BN254)
+
+	return data, err
+}
+
 // execution of circuit function of program
 func EvaluateMimc(backend string, compile bool, in []big.Int, hash []byte) (map[string]time.Duration, error) {
 
@@ -761,7 +802,9 @@ func EvaluateKdc(backend string, compile bool) (map[string]time.Duration, error)
 	SATSin := "a274333afcd102039bb1bc0632e1488858375420a55937c878a6fbdb1915ca94"
 	intermediateHashHSopad := "4b666cdc720a74082b1594c95367f3c71f5124db03add4877e959c6c50c7e3b5"
 	tkSAPPin := "b7c39a10f4650ad160dfe8161ad74020ac50447768894252f7504aafb0c11d36"
+	ivSAPPin := "73fe809b74e260cc7b62b6b0501eb8514a1164a71d73a1c587c40ab0795f36b2"
 	sk := "58e95f7a4abe43fa68c785039f09dce8"
+	siv := "87ee62ba534b4af7a14ee415"
 
 	byteSlice, _ := hex.DecodeString(intermediateHashHSopad)
 	intermediateHashHSopadByteLen := len(byteSlice)
@@ -773,8 +816,12 @@ func EvaluateKdc(backend string, compile bool) (map[string]time.Duration, error)
 	XATSinByteLen := len(byteSlice)
 	byteSlice, _ = hex.DecodeString(tkSAPPin)
 	tkXAPPinByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(ivSAPPin)
+	ivXAPPinByteLen := len(byteSlice)
 	byteSlice, _ = hex.DecodeString(sk)
 	skByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(siv)
+	sivByteLen := len(byteSlice)
 
 	// add padding
 	pad := PadSha256(96)
@@ -790,7 +837,9 @@ func EvaluateKdc(backend string, compile bool) (map[string]time.Duration, error)
 	MSinAssign := StrToIntSlice(MSin, true)
 	XATSinAssign := StrToIntSlice(SATSin, true)
 	tkXAPPinAssign := StrToIntSlice(tkSAPPin, true)
+	ivXAPPinAssign := StrToIntSlice(ivSAPPin, true)
 	skAssign := StrToIntSlice(sk, true)
+	sivAssign := StrToIntSlice(siv, true)
 
 	// witness values preparation
 	assignment := KdcWrapper{
@@ -799,7 +848,9 @@ func EvaluateKdc(backend string, compile bool) (map[string]time.Duration, error)
 		MSin:                   [32]frontend.Variable{},
 		XATSin:                 [32]frontend.Variable{},
 		TkXAPPin:               [32]frontend.Variable{},
+		IvXAPPin:               [32]frontend.Variable{},
 		TkXAPP:                 [16]frontend.Variable{},
+		IvXAPP:                 [12]frontend.Variable{},
 	}
 
 	for i := 0; i < intermediateHashHSopadByteLen; i++ {
@@ -817,9 +868,15 @@ func EvaluateKdc(backend string, compile bool) (map[string]time.Duration, error)
 	for i := 0; i < tkXAPPinByteLen; i++ {
 		assignment.TkXAPPin[i] = tkXAPPinAssign[i]
 	}
+	for i := 0; i < ivXAPPinByteLen; i++ {
+		assignment.IvXAPPin[i] = ivXAPPinAssign[i]
+	}
 	for i := 0; i < skByteLen; i++ {
 		assignment.TkXAPP[i] = skAssign[i]
 	}
+	for i := 0; i < sivByteLen; i++ {
+		assignment.IvXAPP[i] = sivAssign[i]
+	}
 
 	// var circuit kdcServerKey
 	var circuit KdcWrapper
@@ -837,6 +894,7 @@ func EvaluateRecord(backend string, compile bool) (map[string]time.Duration, err
 	iv := "a54613bf2801a84ce693d0a0"
 	chipherChunks := "419a031754a4897806533c6020e9130f6088747b9f9a1e1eba4cb0518a6d5692"
 	plainChunks := "302c353631204575726f227d2c227072696365223a2233383030322e32222c22"
+	sequenceNumber := 0
 	chunkIndex := 32
 	substring := "\"price\""
 	substringStart := 13
@@ -871,6 +929,7 @@ func EvaluateRecord(backend string, compile bool) (map[string]time.Duration, err
 		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
 		Iv:             [12]frontend.Variable{},
 		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+		SequenceNumber: sequenceNumber,
 		ChunkIndex:     chunkIndex,
 		Substring:      make([]frontend.Variable, substringByteLen),
 		SubstringStart: substringStart,
@@ -913,6 +972,195 @@ func EvaluateRecord(backend string, compile bool) (map[string]time.Duration, err
 	return data, err
 }
 
+// execution of circuit function of program
+func EvaluateRecordTag(backend string, compile bool) (map[string]time.Duration, error) {
+
+	log.Debug().Msg("EvaluateRecordTag")
+
+	key := "2872658573f95e87550cb26374e5f667"
+	iv := "a54613bf2801a84ce693d0a0"
+	aad := "1703030033"
+	tag := "4351395b1d403f47d7bfc67cdc97a848"
+	chipherChunks := "9c447efc2411627870169340d24839b2c2801e1a6dd41b50f3f6418c095d54e09e13e3"
+	plainChunks := "7b226e616d65223a22626974636f696e222c227072696365223a223338303032227d17"
+	sequenceNumber := 0
+	chunkIndex := 2
+	substring := "\"price\""
+	substringStart := 18
+	substringEnd := 25
+	valueStart := 27
+	valueEnd := 32
+	threshold := 38002
+
+	// record to bytes
+	byteSlice, _ := hex.DecodeString(chipherChunks)
+	chipherChunksByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(plainChunks)
+	plainChunksByteLen := len(byteSlice)
+	substringByteLen := len(substring)
+
+	log.Debug().Str("length", strconv.Itoa(plainChunksByteLen)).Msg("record tag proof length plaintext/ciphertext")
+
+	// witness definition
+	keyAssign := StrToIntSlice(key, true)
+	ivAssign := StrToIntSlice(iv, true)
+	aadAssign := StrToIntSlice(aad, true)
+	tagAssign := StrToIntSlice(tag, true)
+	chipherChunksAssign := StrToIntSlice(chipherChunks, true)
+	plainChunksAssign := StrToIntSlice(plainChunks, true)
+	substringAssign := StrToIntSlice(substring, false)
+
+	// witness values preparation
+	assignment := RecordTagWrapper{
+		RecordWrapper: RecordWrapper{
+			Key:            [16]frontend.Variable{},
+			PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
+			Iv:             [12]frontend.Variable{},
+			CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+			SequenceNumber: sequenceNumber,
+			ChunkIndex:     chunkIndex,
+			Substring:      make([]frontend.Variable, substringByteLen),
+			SubstringStart: substringStart,
+			SubstringEnd:   substringEnd,
+			ValueStart:     valueStart,
+			ValueEnd:       valueEnd,
+			Threshold:      threshold,
+		},
+		Aad: [5]frontend.Variable{},
+		Tag: [16]frontend.Variable{},
+	}
+
+	// record assign
+	for i := 0; i < 16; i++ {
+		assignment.Key[i] = keyAssign[i]
+		assignment.Tag[i] = tagAssign[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = ivAssign[i]
+	}
+	for i := 0; i < 5; i++ {
+		assignment.Aad[i] = aadAssign[i]
+	}
+	for i := 0; i < plainChunksByteLen; i++ {
+		assignment.PlainChunks[i] = plainChunksAssign[i]
+	}
+	for i := 0; i < chipherChunksByteLen; i++ {
+		assignment.CipherChunks[i] = chipherChunksAssign[i]
+	}
+	for i := 0; i < substringByteLen; i++ {
+		assignment.Substring[i] = substringAssign[i]
+	}
+
+	circuit := RecordTagWrapper{
+		RecordWrapper: RecordWrapper{
+			PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
+			CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+			Substring:      make([]frontend.Variable, substringByteLen),
+			SubstringStart: substringStart,
+			SubstringEnd:   substringEnd,
+			ValueStart:     valueStart,
+			ValueEnd:       valueEnd,
+		},
+	}
+
+	data, err := ProofWithBackend(backend, compile, &circuit, &assignment, ecc.BN254)
+
+	return data, err
+}
+
+func EvaluateRecordChaCha(backend string, compile bool) (map[string]time.Duration, error) {
+
+	log.Debug().Msg("EvaluateRecordChaCha")
+
+	key := "2872658573f95e87550cb26374e5f6672872658573f95e87550cb26374e5f667"
+	iv := "a54613bf2801a84ce693d0a0"
+	aad := "1703030033"
+	tag := "50590ce58fedc4af4179712dd148be47"
+	chipherChunks := "7fef02a656c6e025159a7a87f5345901c9c2ce6c820f10bc5d540287dae2ab7e985932"
+	plainChunks := "7b226e616d65223a22626974636f696e222c227072696365223a223338303032227d17"
+	sequenceNumber := 0
+	chunkIndex := 1
+	substring := "\"price\""
+	substringStart := 18
+	substringEnd := 25
+	valueStart := 27
+	valueEnd := 32
+	threshold := 38002
+
+	// record to bytes
+	byteSlice, _ := hex.DecodeString(chipherChunks)
+	chipherChunksByteLen := len(byteSlice)
+	byteSlice, _ = hex.DecodeString(plainChunks)
+	plainChunksByteLen := len(byteSlice)
+	substringByteLen := len(substring)
+
+	log.Debug().Str("length", strconv.Itoa(plainChunksByteLen)).Msg("record chacha proof length plaintext/ciphertext")
+
+	// witness definition
+	keyAssign := StrToIntSlice(key, true)
+	ivAssign := StrToIntSlice(iv, true)
+	aadAssign := StrToIntSlice(aad, true)
+	tagAssign := StrToIntSlice(tag, true)
+	chipherChunksAssign := StrToIntSlice(chipherChunks, true)
+	plainChunksAssign := StrToIntSlice(plainChunks, true)
+	substringAssign := StrToIntSlice(substring, false)
+
+	// witness values preparation
+	assignment := RecordChaChaWrapper{
+		Key:            [32]frontend.Variable{},
+		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
+		Iv:             [12]frontend.Variable{},
+		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+		SequenceNumber: sequenceNumber,
+		ChunkIndex:     chunkIndex,
+		Substring:      make([]frontend.Variable, substringByteLen),
+		SubstringStart: substringStart,
+		SubstringEnd:   substringEnd,
+		ValueStart:     valueStart,
+		ValueEnd:       valueEnd,
+		Threshold:      threshold,
+		Aad:            [5]frontend.Variable{},
+		Tag:            [16]frontend.Variable{},
+	}
+
+	// record assign
+	for i := 0; i < 32; i++ {
+		assignment.Key[i] = keyAssign[i]
+	}
+	for i := 0; i < 16; i++ {
+		assignment.Tag[i] = tagAssign[i]
+	}
+	for i := 0; i < 12; i++ {
+		assignment.Iv[i] = ivAssign[i]
+	}
+	for i := 0; i < 5; i++ {
+		assignment.Aad[i] = aadAssign[i]
+	}
+	for i := 0; i < plainChunksByteLen; i++ {
+		assignment.PlainChunks[i] = plainChunksAssign[i]
+	}
+	for i := 0; i < chipherChunksByteLen; i++ {
+		assignment.CipherChunks[i] = chipherChunksAssign[i]
+	}
+	for i := 0; i < substringByteLen; i++ {
+		assignment.Substring[i] = substringAssign[i]
+	}
+
+	circuit := RecordChaChaWrapper{
+		PlainChunks:    make([]frontend.Variable, plainChunksByteLen),
+		CipherChunks:   make([]frontend.Variable, chipherChunksByteLen),
+		Substring:      make([]frontend.Variable, substringByteLen),
+		SubstringStart: substringStart,
+		SubstringEnd:   substringEnd,
+		ValueStart:     valueStart,
+		ValueEnd:       valueEnd,
+	}
+
+	data, err := ProofWithBackend(backend, compile, &circuit, &assignment, ecc.BN254)
+
+	return data, err
+}
+
 func EvaluateXor(backend string, compile bool, in, mask, out string) (map[string]time.Duration, error) {
 
 	log.Debug().Msg("EvaluateXor")
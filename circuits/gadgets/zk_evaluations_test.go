@@ -21,6 +21,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"os"
 	"testing"
@@ -60,6 +61,7 @@ var (
 	eval_constraints  bool
 	shacal2_circuit   bool
 	sha256_circuit    bool
+	sha384_circuit    bool
 	aes128_circuit    bool
 	authtag_circuit   bool
 	gcm_circuit       bool
@@ -74,6 +76,7 @@ var (
 	byte_size         int
 	ps                string
 	compile           bool
+	cipher_suite      string
 )
 
 func TestMain(m *testing.M) {
@@ -103,6 +106,9 @@ func TestMain(m *testing.M) {
 	// individual evaluation flags
 	flag.BoolVar(&sha256_circuit, "sha256", false, "evaluates sha256 circuit")
 
+	// individual evaluation flags
+	flag.BoolVar(&sha384_circuit, "sha384", false, "evaluates sha384 circuit")
+
 	// individual evaluation flags
 	flag.BoolVar(&aes128_circuit, "aes128", false, "evaluates aes128 circuit")
 
@@ -145,6 +151,9 @@ func TestMain(m *testing.M) {
 	// indicate if circuit should be compiled only
 	flag.BoolVar(&compile, "compile", false, "returns program after circuit compilation, no timing data is captured.")
 
+	// indicate tls13 cipher suite (applies only to session commit, session data, and oracle circuits)
+	flag.StringVar(&cipher_suite, "cipher-suite", "TLS_AES_128_GCM_SHA256", "switch between TLS_AES_128_GCM_SHA256 and TLS_AES_256_GCM_SHA384 cipher suites. default: TLS_AES_128_GCM_SHA256.")
+
 	flag.Parse()
 
 	// Default level for this example is info, unless debug flag is present
@@ -166,6 +175,12 @@ func TestMain(m *testing.M) {
 
 func TestAll(t *testing.T) {
 
+	// verify cipher suite
+	cs, err := ParseCipherSuite(cipher_suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// session commit derivation: kdc + authtag + key commit
 	if session_commit {
 		data := map[string]string{}
@@ -179,7 +194,7 @@ func TestAll(t *testing.T) {
 
 		var s []map[string]time.Duration
 		for i := iterations; i > 0; i-- {
-			data, err := EvaluateSessionCommit(ps, compile)
+			data, err := EvaluateSessionCommit(ps, compile, cs)
 			if err != nil {
 				log.Error().Msg("sc.Evaluate()")
 			}
@@ -209,7 +224,7 @@ func TestAll(t *testing.T) {
 
 		var s []map[string]time.Duration
 		for i := iterations; i > 0; i-- {
-			data, err := EvaluateSessionData(ps, compile)
+			data, err := EvaluateSessionData(ps, compile, cs)
 			if err != nil {
 				log.Error().Msg("iv.ClientExecute()")
 			}
@@ -239,7 +254,7 @@ func TestAll(t *testing.T) {
 
 		var s []map[string]time.Duration
 		for i := iterations; i > 0; i-- {
-			data, err := EvaluateOracle(ps, compile)
+			data, err := EvaluateOracle(ps, compile, cs)
 			if err != nil {
 				log.Error().Msg("kd.Evaluate()")
 			}
@@ -321,6 +336,39 @@ func TestAll(t *testing.T) {
 		StoreM(data, "./jsons/", filename)
 	}
 
+	// sha384 evaluation
+	if sha384_circuit {
+		data := map[string]string{}
+		data["iterations"] = strconv.Itoa(iterations)
+		data["backend"] = ps
+		if byte_size != 0 {
+			data["data_size"] = strconv.Itoa(byte_size)
+		} else {
+			data["data_size"] = "default"
+		}
+
+		// generate data for evaluation
+		byteArray := make([]byte, byte_size)
+		in := hex.EncodeToString(byteArray)
+		sum := sha512.Sum384(byteArray)
+		hash := hex.EncodeToString(sum[:])
+
+		var s []map[string]time.Duration
+		for i := iterations; i > 0; i-- {
+			data, err := EvaluateSha384(ps, compile, in, hash)
+			if err != nil {
+				log.Error().Msg("EvaluateSha384()")
+			}
+			s = append(s, data)
+		}
+		if compile {
+			return
+		}
+		AddStats(data, s, false)
+		filename := "sha384_" + data["iterations"] + "_" + data["backend"] + "_" + data["data_size"]
+		StoreM(data, "./jsons/", filename)
+	}
+
 	// aes128 evaluation
 	if aes128_circuit {
 		data := map[string]string{}
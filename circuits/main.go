@@ -22,6 +22,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -77,6 +78,9 @@ func main() {
 	// checks for -tls13-deco-proxy flag
 	kdc_decoproxy := flag.Bool("tls13-deco-proxy", false, "tls13 key commit, authtag and record proof")
 
+	// checks for -claim flag
+	claim := flag.String("claim", "", "path to a json claim spec which is compiled into a circuit, set up, proven and verified")
+
 	// checks for -evaluate-constraints flag
 	// evalutes most of the functions, used for quick testing
 	eval_constraints := flag.Bool("evaluate-constraints", false, "evaluates all circuits with different backends. use the backend flag to specify the backend")
@@ -87,6 +91,9 @@ func main() {
 	// individual evaluation flags
 	sha256_circuit := flag.Bool("sha256", false, "evaluates sha256 circuit")
 
+	// individual evaluation flags
+	sha384_circuit := flag.Bool("sha384", false, "evaluates sha384 circuit")
+
 	// individual evaluation flags
 	sha2_circuit := flag.Bool("sha2", false, "evaluates sha2 circuit")
 
@@ -117,6 +124,8 @@ func main() {
 
 	// individual evaluation flags
 	record_circuit := flag.Bool("record", false, "evaluates record circuit")
+	record_tag_circuit := flag.Bool("record-tag", false, "evaluates record circuit with gcm authentication tag verification")
+	record_chacha_circuit := flag.Bool("record-chacha", false, "evaluates chacha20-poly1305 record circuit with authentication tag verification")
 
 	// individual evaluation flags
 	xor_circuit := flag.Bool("xor", false, "evaluates xor circuit")
@@ -142,6 +151,9 @@ func main() {
 	// indicate if circuit should be compiled only
 	compile := flag.Bool("compile", false, "returns program after circuit compilation, no timing data is captured.")
 
+	// indicate tls13 cipher suite (applies only to session commit, session data, and oracle circuits)
+	cipher_suite := flag.String("cipher-suite", "TLS_AES_128_GCM_SHA256", "switch between TLS_AES_128_GCM_SHA256 and TLS_AES_256_GCM_SHA384 cipher suites, chacha20-poly1305 records are evaluated with -record-chacha. default: TLS_AES_128_GCM_SHA256.")
+
 	flag.Parse()
 
 	// Default level for this example is info, unless debug flag is present
@@ -158,6 +170,13 @@ func main() {
 	// activated check
 	log.Debug().Msg("Debugging activated.")
 
+	// verify cipher suite
+	cs, err := g.ParseCipherSuite(*cipher_suite)
+	if err != nil || !cs.IsAESGCM() {
+		log.Error().Msg("cipher-suite must be TLS_AES_128_GCM_SHA256 or TLS_AES_256_GCM_SHA384.")
+		return
+	}
+
 	// session commit derivation: kdc + authtag + key commit
 	if *session_commit {
 		data := map[string]string{}
@@ -171,7 +190,7 @@ func main() {
 
 		var s []map[string]time.Duration
 		for i := *iterations; i > 0; i-- {
-			data, err := g.EvaluateSessionCommit(*ps, *compile)
+			data, err := g.EvaluateSessionCommit(*ps, *compile, cs)
 			if err != nil {
 				log.Error().Msg("g.EvaluateSessionCommit()")
 			}
@@ -201,7 +220,7 @@ func main() {
 
 		var s []map[string]time.Duration
 		for i := *iterations; i > 0; i-- {
-			data, err := g.EvaluateSessionData(*ps, *compile)
+			data, err := g.EvaluateSessionData(*ps, *compile, cs)
 			if err != nil {
 				log.Error().Msg("g.EvaluateSessionData()")
 			}
@@ -231,7 +250,7 @@ func main() {
 
 		var s []map[string]time.Duration
 		for i := *iterations; i > 0; i-- {
-			data, err := g.EvaluateOracle(*ps, *compile)
+			data, err := g.EvaluateOracle(*ps, *compile, cs)
 			if err != nil {
 				log.Error().Msg("g.EvaluateOracle()")
 			}
@@ -278,6 +297,33 @@ func main() {
 		g.StoreM(data, "./jsons/", filename)
 	}
 
+	// claim spec circuit, runs at least once
+	if *claim != "" {
+		data := map[string]string{}
+		data["iterations"] = strconv.Itoa(max(*iterations, 1))
+		data["backend"] = *ps
+		data["data_size"] = "default"
+
+		var s []map[string]time.Duration
+		for i := max(*iterations, 1); i > 0; i-- {
+			data, err := g.EvaluateClaim(*ps, *compile, *claim)
+			if err != nil {
+				log.Error().Err(err).Msg("g.EvaluateClaim()")
+				return
+			}
+			s = append(s, data)
+		}
+
+		// return if only interested in circuit constraints
+		if *compile {
+			return
+		}
+
+		g.AddStats(data, s, false)
+		filename := "claim_" + data["iterations"] + "_" + data["backend"] + "_" + data["data_size"]
+		g.StoreM(data, "./jsons/", filename)
+	}
+
 	// shacal2 evaluation
 	if *shacal2_circuit {
 		data := map[string]string{}
@@ -379,6 +425,39 @@ func main() {
 		g.StoreM(data, "./jsons/", filename)
 	}
 
+	// sha384 evaluation
+	if *sha384_circuit {
+		data := map[string]string{}
+		data["iterations"] = strconv.Itoa(*iterations)
+		data["backend"] = *ps
+		if *byte_size != 0 {
+			data["data_size"] = strconv.Itoa(*byte_size)
+		} else {
+			data["data_size"] = "default"
+		}
+
+		// generate data for evaluation
+		byteArray := make([]byte, *byte_size)
+		in := hex.EncodeToString(byteArray)
+		sum := sha512.Sum384(byteArray)
+		hash := hex.EncodeToString(sum[:])
+
+		var s []map[string]time.Duration
+		for i := *iterations; i > 0; i-- {
+			data, err := g.EvaluateSha384(*ps, *compile, in, hash)
+			if err != nil {
+				log.Error().Msg("g.EvaluateSha384()")
+			}
+			s = append(s, data)
+		}
+		if *compile {
+			return
+		}
+		g.AddStats(data, s, false)
+		filename := "sha384_" + data["iterations"] + "_" + data["backend"] + "_" + data["data_size"]
+		g.StoreM(data, "./jsons/", filename)
+	}
+
 	// zkopen evaluation
 	if *zkopen_circuit {
 		data := map[string]string{}
@@ -967,6 +1046,62 @@ func main() {
 		g.StoreM(data, "./jsons/", filename)
 	}
 
+	if *record_tag_circuit {
+		data := map[string]string{}
+		data["iterations"] = strconv.Itoa(*iterations)
+		data["backend"] = *ps
+		if *byte_size != 0 {
+			data["data_size"] = strconv.Itoa(*byte_size)
+		} else {
+			data["data_size"] = "default"
+		}
+
+		var s []map[string]time.Duration
+		for i := *iterations; i > 0; i-- {
+			data, err := g.EvaluateRecordTag(*ps, *compile)
+			if err != nil {
+				log.Error().Msg("g.EvaluateRecordTag()")
+			}
+			s = append(s, data)
+		}
+
+		// return if only interested in circuit constraints
+		if *compile {
+			return
+		}
+		g.AddStats(data, s, false)
+		filename := "recordtag_" + data["iterations"] + "_" + data["backend"] + "_" + data["data_size"]
+		g.StoreM(data, "./jsons/", filename)
+	}
+
+	if *record_chacha_circuit {
+		data := map[string]string{}
+		data["iterations"] = strconv.Itoa(*iterations)
+		data["backend"] = *ps
+		if *byte_size != 0 {
+			data["data_size"] = strconv.Itoa(*byte_size)
+		} else {
+			data["data_size"] = "default"
+		}
+
+		var s []map[string]time.Duration
+		for i := *iterations; i > 0; i-- {
+			data, err := g.EvaluateRecordChaCha(*ps, *compile)
+			if err != nil {
+				log.Error().Msg("g.EvaluateRecordChaCha()")
+			}
+			s = append(s, data)
+		}
+
+		// return if only interested in circuit constraints
+		if *compile {
+			return
+		}
+		g.AddStats(data, s, false)
+		filename := "recordchacha_" + data["iterations"] + "_" + data["backend"] + "_" + data["data_size"]
+		g.StoreM(data, "./jsons/", filename)
+	}
+
 	// xor evaluation
 	if *xor_circuit {
 		data := map[string]string{}